@@ -0,0 +1,54 @@
+package shortdescription
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Backend selects the upstream API descriptions are fetched from.
+type Backend string
+
+const (
+	// BackendWikitext scans the page wikitext, as given by the MediaWiki Action API.
+	BackendWikitext Backend = "wikitext"
+	// BackendDescription asks the MediaWiki Action API for the description property, which
+	// batches well and avoids downloading the wikitext, except for the pages that have no
+	// local description.
+	BackendDescription Backend = "description"
+	// BackendSummary uses the REST API page summary, which is only a few hundred bytes long
+	// but may hold the Wikidata description of pages without a local one.
+	BackendSummary Backend = "summary"
+)
+
+// backend fetches descriptions of already normalized titles.
+type backend interface {
+	// describe also looks for the infobox if any of the fields needs it, when the backend
+	// can do it along the way.
+	describe(ctx context.Context, title, userAgent string, fields []Field) (description, error)
+
+	// describeBatch adds the descriptions it finds to descrs, leaving out titles without one.
+	describeBatch(ctx context.Context, titles []string, userAgent string, descrs map[string]description) error
+}
+
+type fetchFunc func(ctx context.Context, url, userAgent string) (*http.Response, error)
+
+func newBackend(cfg Config, fetch fetchFunc) (backend, error) {
+	wikitext := wikitextBackend{
+		fetch:          fetch,
+		metrics:        cfg.Metrics,
+		scanLimitBytes: cfg.ScanLimitBytes,
+		scanLimitLines: cfg.ScanLimitLines,
+	}
+
+	switch cfg.Backend {
+	case BackendWikitext:
+		return wikitext, nil
+	case BackendDescription:
+		return descriptionBackend{fetch: fetch, wikitext: wikitext}, nil
+	case BackendSummary:
+		return summaryBackend{fetch}, nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q", cfg.Backend)
+	}
+}
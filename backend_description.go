@@ -0,0 +1,82 @@
+package shortdescription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const localDescriptionURL apiURL = "https://en.wikipedia.org/w/api.php?action=query&prop=description%7Cinfo&descprefersource=local&formatversion=2&format=json&titles="
+
+func getLocalDescriptionURL(titles []string) string {
+	return string(localDescriptionURL) + url.QueryEscape(strings.Join(titles, "|"))
+}
+
+// descriptionBackend uses the description property set by the page through its short
+// description template. When a page has no local description the property is left out
+// (or holds the Wikidata one), so its wikitext is parsed instead. That way explicitly
+// empty descriptions are still told apart from missing ones.
+type descriptionBackend struct {
+	fetch    fetchFunc
+	wikitext wikitextBackend
+}
+
+func (b descriptionBackend) describe(ctx context.Context, title, userAgent string, _ []Field) (description, error) {
+	descrs := make(map[string]description, 1)
+
+	if err := b.describeBatch(ctx, []string{title}, userAgent, descrs); err != nil {
+		return description{}, err
+	}
+
+	descr, ok := descrs[title]
+	if !ok {
+		return description{}, fmt.Errorf("short description %w", ErrNotFound)
+	}
+
+	return descr, nil
+}
+
+func (b descriptionBackend) describeBatch(ctx context.Context, titles []string, userAgent string, descrs map[string]description) error {
+	var withoutLocal []string
+
+	for len(titles) > 0 {
+		n := len(titles)
+		if n > maxBatchTitles {
+			n = maxBatchTitles
+		}
+
+		res, err := b.fetch(ctx, getLocalDescriptionURL(titles[:n]), userAgent)
+		if err != nil {
+			return err
+		}
+
+		var dr descriptionResponse
+
+		err = json.NewDecoder(res.Body).Decode(&dr)
+		res.Body.Close()
+
+		if err != nil {
+			return fmt.Errorf("%w: cannot decode description response: %v", ErrUpstream, err)
+		}
+
+		for _, page := range dr.Query.Pages {
+			switch {
+			case page.Missing:
+			case page.DescriptionSource == "local" && page.Description != "":
+				descrs[page.Title] = description{text: page.Description, revision: page.LastRevID}
+			default:
+				withoutLocal = append(withoutLocal, page.Title)
+			}
+		}
+
+		titles = titles[n:]
+	}
+
+	if len(withoutLocal) == 0 {
+		return nil
+	}
+
+	return b.wikitext.describeBatch(ctx, withoutLocal, userAgent, descrs)
+}
@@ -0,0 +1,126 @@
+package shortdescription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// maxBatchTitles is the maximum amount of titles the MediaWiki API accepts in a single query.
+const maxBatchTitles = 50
+
+const batchDescriptionsURL apiURL = "https://en.wikipedia.org/w/api.php?action=query&prop=revisions&formatversion=2&format=json&rvprop=ids%7Ccontent&rvslots=main&titles="
+
+func getBatchDescriptionsURL(titles []string) string {
+	return string(batchDescriptionsURL) + url.QueryEscape(strings.Join(titles, "|"))
+}
+
+type wikiResponse struct {
+	Query struct {
+		Pages []wikiPage `json:"pages"`
+	} `json:"query"`
+}
+
+type wikiPage struct {
+	Title     string `json:"title"`
+	Missing   bool   `json:"missing"`
+	Revisions []struct {
+		RevID int64 `json:"revid"`
+		Slots struct {
+			Main struct {
+				Content string `json:"content"`
+			} `json:"main"`
+		} `json:"slots"`
+	} `json:"revisions"`
+}
+
+// wikitextBackend scans the page wikitext for its short description.
+//
+// Single lookups are streamed, and stop as soon as the scan limits are reached: the short
+// description is conventionally near the top of the page.
+type wikitextBackend struct {
+	fetch          fetchFunc
+	metrics        Metrics
+	scanLimitBytes int64
+	scanLimitLines int
+}
+
+func (b wikitextBackend) describe(ctx context.Context, title, userAgent string, fields []Field) (description, error) {
+	res, err := b.fetch(ctx, getShortDescriptionURL(title), userAgent)
+	if err != nil {
+		return description{}, err
+	}
+
+	defer res.Body.Close()
+
+	body := &byteCounter{r: res.Body, limit: b.scanLimitBytes}
+	defer func() { b.metrics.ObserveBytesRead(body.read) }()
+
+	revision := &revisionReader{RuneScanner: newJSONTextReader(body)}
+
+	descr, err := readShortDescription(&lineLimiter{
+		RuneScanner: revision,
+		limit:       b.scanLimitLines,
+	}, needsInfobox(fields))
+	descr.revision = revision.id
+
+	return descr, err
+}
+
+// describeBatch queries the API in batches of maxBatchTitles.
+func (b wikitextBackend) describeBatch(ctx context.Context, titles []string, userAgent string, descrs map[string]description) error {
+	for len(titles) > 0 {
+		n := len(titles)
+		if n > maxBatchTitles {
+			n = maxBatchTitles
+		}
+
+		if err := b.fetchBatch(ctx, titles[:n], userAgent, descrs); err != nil {
+			return err
+		}
+
+		titles = titles[n:]
+	}
+
+	return nil
+}
+
+func (b wikitextBackend) fetchBatch(ctx context.Context, titles []string, userAgent string, descrs map[string]description) error {
+	res, err := b.fetch(ctx, getBatchDescriptionsURL(titles), userAgent)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	body := &byteCounter{r: res.Body}
+	defer func() { b.metrics.ObserveBytesRead(body.read) }()
+
+	var wr wikiResponse
+	if err := json.NewDecoder(body).Decode(&wr); err != nil {
+		return fmt.Errorf("%w: cannot decode batch response: %v", ErrUpstream, err)
+	}
+
+	for _, page := range wr.Query.Pages {
+		if page.Missing || len(page.Revisions) == 0 {
+			continue
+		}
+
+		descr, err := parseShortDescription(page.Revisions[0].Slots.Main.Content)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		descr.revision = page.Revisions[0].RevID
+		descrs[page.Title] = descr
+	}
+
+	return nil
+}
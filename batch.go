@@ -0,0 +1,112 @@
+package shortdescription
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MaxBatchSize is the maximum amount of persons that can be described at once.
+const MaxBatchSize = maxBatchTitles
+
+// Batch holds the short descriptions of several persons, in the order they were asked for.
+type Batch struct {
+	Descriptions []ShortDescription `json:"descriptions"`
+	NotFound     []string           `json:"notFound,omitempty"`
+}
+
+// ShortDescriptions looks up the short descriptions of up to MaxBatchSize persons at once,
+// in as few upstream requests as the backend allows.
+func (d Describer) ShortDescriptions(ctx context.Context, persons []string, userAgent string) (Batch, error) {
+	if len(persons) == 0 || len(persons) > MaxBatchSize {
+		return Batch{}, fmt.Errorf("%w: between 1 and %d persons can be described at once", ErrInvalidArgument, MaxBatchSize)
+	}
+
+	if userAgent == "" {
+		return Batch{}, fmt.Errorf("%w: userAgent is empty", ErrInvalidArgument)
+	}
+
+	titles := make([]string, 0, len(persons))
+	seen := make(map[string]bool, len(persons))
+
+	for _, person := range persons {
+		if person == "" {
+			return Batch{}, fmt.Errorf("%w: person is empty", ErrInvalidArgument)
+		}
+
+		if strings.Contains(person, "|") {
+			// it would be taken as several titles upstream
+			return Batch{}, fmt.Errorf("%w: person cannot contain '|'", ErrInvalidArgument)
+		}
+
+		title := normalizeTitle(person)
+		if !seen[title] {
+			seen[title] = true
+			titles = append(titles, title)
+		}
+	}
+
+	descrs, err := d.shortDescriptions(ctx, titles, userAgent)
+	if err != nil {
+		return Batch{}, err
+	}
+
+	batch := Batch{Descriptions: make([]ShortDescription, 0, len(titles))}
+
+	for _, title := range titles {
+		descr, ok := descrs[title]
+		if !ok {
+			batch.NotFound = append(batch.NotFound, title)
+			continue
+		}
+
+		batch.Descriptions = append(batch.Descriptions, descr.shortDescription(title))
+	}
+
+	return batch, nil
+}
+
+// shortDescriptions looks up the short descriptions of already normalized titles, querying
+// the backend for those that are not cached.
+//
+// The result is keyed by title and leaves out titles without a short description. On error,
+// the descriptions found so far are still returned.
+func (d Describer) shortDescriptions(ctx context.Context, titles []string, userAgent string) (map[string]description, error) {
+	descrs := make(map[string]description, len(titles))
+
+	var missing []string
+
+	_, span := d.tracer.Start(ctx, "cache.lookup", trace.WithAttributes(
+		attrCache.String(CacheDescriptions),
+		attrTitles.StringSlice(titles),
+	))
+
+	for _, title := range titles {
+		if descr, ok := d.cache.Get(title); ok {
+			descrs[title] = descr
+			continue
+		}
+
+		missing = append(missing, title)
+	}
+
+	span.SetAttributes(attrCacheHits.Int(len(descrs)))
+	span.End()
+
+	if len(missing) == 0 {
+		return descrs, nil
+	}
+
+	fetched := make(map[string]description, len(missing))
+
+	err := d.backend.describeBatch(ctx, missing, userAgent, fetched)
+
+	for title, descr := range fetched {
+		d.store(title, descr)
+		descrs[title] = descr
+	}
+
+	return descrs, err
+}
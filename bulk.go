@@ -0,0 +1,326 @@
+package shortdescription
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// BulkResult is the outcome of a line of a bulk request. Results come in the order they
+// complete, so Line and ID tell which line they answer.
+type BulkResult struct {
+	Line int             `json:"line"`         // of the request, starting at 1
+	ID   json.RawMessage `json:"id,omitempty"` // as sent in the line, if it was an object
+
+	ShortDescription
+
+	Code  string `json:"code,omitempty"` // set on error, as in ErrorResponse
+	Error string `json:"error,omitempty"`
+}
+
+// bulkLine is a line of a bulk request: either a title, or an object with an optional id.
+type bulkLine struct {
+	ID     json.RawMessage `json:"id"`
+	Person string          `json:"person"`
+	Title  string          `json:"title"` // an alias of person
+}
+
+type bulkItem struct {
+	line   int
+	id     json.RawMessage
+	person string // as sent
+	err    error
+}
+
+// Bulk looks up the short descriptions of the persons read from r, one per line, and calls
+// emit with each result as soon as it completes. Lines are either titles or JSON objects such
+// as {"id": 1, "person": "Yoshua Bengio"}, whose id is echoed in the result. Blank lines are
+// skipped.
+//
+// Up to Config.BulkConcurrency batches of MaxBatchSize persons are looked up at once, and r is
+// only read as fast as the results are emitted. Lines that cannot be described are emitted
+// with an error code, while Bulk stops and fails if ctx is done, if emit fails, if r cannot
+// be read or has more than Config.BulkMaxPersons lines.
+//
+// When ctx comes from Describer.Admit, every batch but the first takes another token from
+// the rate limit of the client, and the lines of the batches it cannot pay for are emitted
+// with the rate_limited code.
+func (d Describer) Bulk(ctx context.Context, r io.Reader, userAgent string, emit func(BulkResult) error) error {
+	return d.bulk(ctx, userAgent, emit, func(ctx context.Context, items chan<- bulkItem) error {
+		return readBulk(ctx, r, d.bulkMaxPersons, items)
+	})
+}
+
+// BulkPersons is Bulk for persons that are already at hand. The Line of each result is the
+// position of its person in persons, starting at 1.
+func (d Describer) BulkPersons(ctx context.Context, persons []string, userAgent string, emit func(BulkResult) error) error {
+	if len(persons) > d.bulkMaxPersons {
+		return fmt.Errorf("%w: cannot look up more than %d persons at once", ErrInvalidArgument, d.bulkMaxPersons)
+	}
+
+	return d.bulk(ctx, userAgent, emit, func(ctx context.Context, items chan<- bulkItem) error {
+		for i, person := range persons {
+			select {
+			case items <- bulkItem{line: i + 1, person: person}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		return nil
+	})
+}
+
+// bulk describes the items sent by read, which must stop when ctx is done.
+func (d Describer) bulk(ctx context.Context, userAgent string, emit func(BulkResult) error,
+	read func(ctx context.Context, items chan<- bulkItem) error,
+) error {
+	if userAgent == "" {
+		return fmt.Errorf("%w: userAgent is empty", ErrInvalidArgument)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	items := make(chan bulkItem, MaxBatchSize)
+	results := make(chan BulkResult, MaxBatchSize)
+
+	var readErr error
+
+	go func() {
+		defer close(items)
+		readErr = read(ctx, items)
+	}()
+
+	var (
+		wg      sync.WaitGroup
+		batches int32 // described so far, accessed atomically
+	)
+
+	for i := 0; i < d.bulkConcurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for item := range items {
+				batch := []bulkItem{item}
+
+				// take whatever else is already waiting, without waiting for a full batch
+			fill:
+				for len(batch) < MaxBatchSize {
+					select {
+					case item, ok := <-items:
+						if !ok {
+							break fill
+						}
+
+						batch = append(batch, item)
+					default:
+						break fill
+					}
+				}
+
+				// the first batch is paid for by the request itself
+				if atomic.AddInt32(&batches, 1) > 1 {
+					if err := d.charge(ctx, 1); err != nil {
+						for i := range batch {
+							if batch[i].err == nil {
+								batch[i].err = err
+							}
+						}
+					}
+				}
+
+				d.describeBulk(ctx, batch, userAgent, results)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var emitErr error
+
+	for result := range results {
+		if emitErr != nil {
+			continue // drain, so that the workers can stop
+		}
+
+		if emitErr = emit(result); emitErr != nil {
+			cancel()
+		}
+	}
+
+	switch {
+	case emitErr != nil:
+		return emitErr
+	case readErr != nil:
+		return readErr
+	default:
+		return ctx.Err()
+	}
+}
+
+// maxBulkLineLen is the longest line a bulk request can have, which is far more than any
+// title or bulkLine needs.
+const maxBulkLineLen = 4 << 10
+
+// bufferBulk reads a whole bulk request, failing as Bulk would if it has more than maxLines
+// lines or a line longer than maxBulkLineLen, so that it can be refused before answering.
+func bufferBulk(r io.Reader, maxLines int) ([]byte, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 512), maxBulkLineLen)
+
+	var buf bytes.Buffer
+
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		if line > maxLines {
+			return nil, fmt.Errorf("%w: bulk requests cannot have more than %d lines", ErrInvalidArgument, maxLines)
+		}
+
+		buf.Write(scanner.Bytes())
+		buf.WriteByte('\n')
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: line %d could not be read: %v", ErrInvalidArgument, line+1, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// readBulk sends the lines of r to items until r ends, has more than maxLines lines, or ctx is
+// done. Lines that cannot be parsed are sent with an error.
+func readBulk(ctx context.Context, r io.Reader, maxLines int, items chan<- bulkItem) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 512), maxBulkLineLen)
+
+	line := 0
+
+	send := func(item bulkItem) bool {
+		select {
+		case items <- item:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for scanner.Scan() {
+		line++
+
+		if line > maxLines {
+			err := fmt.Errorf("%w: bulk requests cannot have more than %d lines", ErrInvalidArgument, maxLines)
+			send(bulkItem{line: line, err: err})
+
+			return err
+		}
+
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		item := bulkItem{line: line, person: string(text)}
+
+		if text[0] == '{' {
+			var l bulkLine
+			if err := json.Unmarshal(text, &l); err != nil {
+				item = bulkItem{line: line, err: fmt.Errorf("%w: the line is not a valid JSON object: %v", ErrInvalidArgument, err)}
+			} else {
+				item = bulkItem{line: line, id: l.ID, person: l.Person}
+				if item.person == "" {
+					item.person = l.Title
+				}
+			}
+		}
+
+		if !send(item) {
+			return ctx.Err()
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err() // such as when the client disconnects
+		}
+
+		err = fmt.Errorf("%w: line %d could not be read: %v", ErrInvalidArgument, line+1, err)
+
+		// let the client know before failing, as it may only be reading the results
+		send(bulkItem{line: line + 1, err: err})
+
+		return err
+	}
+
+	return nil
+}
+
+// describeBulk looks up a batch of bulk items, sending a result for each of them.
+func (d Describer) describeBulk(ctx context.Context, batch []bulkItem, userAgent string, results chan<- BulkResult) {
+	titles := make([]string, 0, len(batch))
+
+	for i, item := range batch {
+		switch {
+		case item.err != nil:
+		case item.person == "":
+			batch[i].err = fmt.Errorf("%w: person is empty", ErrInvalidArgument)
+		case strings.Contains(item.person, "|"):
+			// it would be taken as several titles upstream
+			batch[i].err = fmt.Errorf("%w: person cannot contain '|'", ErrInvalidArgument)
+		default:
+			titles = append(titles, normalizeTitle(item.person))
+		}
+	}
+
+	var (
+		descrs map[string]description
+		err    error
+	)
+
+	if len(titles) > 0 {
+		descrs, err = d.shortDescriptions(ctx, titles, userAgent)
+	}
+
+	for _, item := range batch {
+		result := BulkResult{Line: item.line, ID: item.id}
+
+		if item.err == nil {
+			title := normalizeTitle(item.person)
+
+			if descr, ok := descrs[title]; ok {
+				result.ShortDescription = descr.shortDescription(title)
+			} else if item.err = err; item.err == nil {
+				item.err = fmt.Errorf("%w: %s", ErrNotFound, title)
+			}
+		}
+
+		if item.err != nil {
+			result.Person = item.person
+
+			_, result.Code = errorCode(item.err)
+			if result.Error = errorMessages[result.Code]; result.Error == "" {
+				result.Error = item.err.Error()
+			}
+		}
+
+		select {
+		case results <- result:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
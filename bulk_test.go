@@ -0,0 +1,311 @@
+package shortdescription_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+func TestBulk(t *testing.T) {
+	var calls int32
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo:     testContactInfo,
+		HttpClient:      suggestUpstream(&calls, 0),
+		BulkConcurrency: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := strings.Join([]string{
+		"Yoshua Bengio",
+		`{"id": "a", "person": "yoshua_Bengio"}`,
+		"",
+		`{"id": 3, "title": "Yoshua Lo"}`,
+		`{"id": 4}`,
+		`{"id": 5, "person": "Yoshua Bengio|Yoshua Lo"}`,
+		`{"person": `,
+	}, "\n")
+
+	var results []shortdescription.BulkResult
+
+	err = descriptor.Bulk(context.Background(), strings.NewReader(input), testUserAgent, func(result shortdescription.BulkResult) error {
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Line < results[j].Line })
+
+	bengio := shortdescription.ShortDescription{Person: testPerson, Description: testDescription}
+
+	expected := []shortdescription.BulkResult{
+		{Line: 1, ShortDescription: bengio},
+		{Line: 2, ID: json.RawMessage(`"a"`), ShortDescription: bengio},
+		{Line: 4, ID: json.RawMessage(`3`), ShortDescription: shortdescription.ShortDescription{Person: "Yoshua Lo"},
+			Code: "not_found", Error: "short description not found"},
+		{Line: 5, ID: json.RawMessage(`4`), Code: "invalid_argument", Error: "invalid argument: person is empty"},
+		{Line: 6, ID: json.RawMessage(`5`), ShortDescription: shortdescription.ShortDescription{Person: "Yoshua Bengio|Yoshua Lo"},
+			Code: "invalid_argument", Error: "invalid argument: person cannot contain '|'"},
+		{Line: 7, Code: "invalid_argument", Error: "invalid argument: the line is not a valid JSON object: unexpected end of JSON input"},
+	}
+
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("wanted %+v, got %+v", expected, results)
+	}
+
+	if calls != 1 {
+		t.Errorf("wanted the lines to be looked up in a single batch, got %v upstream requests", calls)
+	}
+}
+
+// endlessReader repeats a line forever.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	return copy(p, "Yoshua Bengio\n"), nil
+}
+
+func TestBulkStops(t *testing.T) {
+	var calls int32
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  suggestUpstream(&calls, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errStop := errors.New("stop")
+	emitted := 0
+
+	err = descriptor.Bulk(context.Background(), endlessReader{}, testUserAgent, func(shortdescription.BulkResult) error {
+		if emitted++; emitted == 100 {
+			return errStop
+		}
+
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("wanted %v, got %v", errStop, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err = descriptor.Bulk(ctx, endlessReader{}, testUserAgent, func(shortdescription.BulkResult) error {
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestBulkHandler(t *testing.T) {
+	var calls int32
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  suggestUpstream(&calls, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(shortdescription.NewHandler(descriptor))
+	defer server.Close()
+
+	body := "Yoshua Bengio\n{\"id\": 2, \"person\": \"Jane Doe\"}\n"
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/bulk", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("User-Agent", testUserAgent)
+
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	if contentType := res.Header.Get("Content-Type"); contentType != "application/x-ndjson" {
+		t.Errorf("wanted an NDJSON response, got %q", contentType)
+	}
+
+	var results []string
+
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		results = append(results, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// results come as they complete
+	sort.Strings(results)
+
+	expected := []string{
+		`{"line":1,"person":"Yoshua Bengio","description":"Canadian computer scientist"}`,
+		`{"line":2,"id":2,"person":"Jane Doe","code":"not_found","error":"short description not found"}`,
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("wanted %v, got %v", expected, results)
+	}
+}
+
+func TestBulkLimits(t *testing.T) {
+	var calls int32
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo:     testContactInfo,
+		HttpClient:      suggestUpstream(&calls, 0),
+		BulkConcurrency: 1,
+		BulkMaxPersons:  3 * shortdescription.MaxBatchSize,
+		ClientRate:      1e-3,
+		ClientBurst:     1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("too many lines", func(t *testing.T) {
+		input := strings.Repeat("Yoshua Bengio\n", 3*shortdescription.MaxBatchSize+1)
+
+		var last shortdescription.BulkResult
+
+		err := descriptor.Bulk(context.Background(), strings.NewReader(input), testUserAgent, func(result shortdescription.BulkResult) error {
+			last = result
+			return nil
+		})
+		if !errors.Is(err, shortdescription.ErrInvalidArgument) {
+			t.Errorf("wanted %v, got %v", shortdescription.ErrInvalidArgument, err)
+		}
+
+		if last.Line != 3*shortdescription.MaxBatchSize+1 || last.Code != "invalid_argument" {
+			t.Errorf("wanted the extra line to be answered with invalid_argument, got %+v", last)
+		}
+
+		persons := make([]string, 3*shortdescription.MaxBatchSize+1)
+
+		err = descriptor.BulkPersons(context.Background(), persons, testUserAgent, func(shortdescription.BulkResult) error {
+			t.Error("wanted no results")
+			return nil
+		})
+		if !errors.Is(err, shortdescription.ErrInvalidArgument) {
+			t.Errorf("wanted %v, got %v", shortdescription.ErrInvalidArgument, err)
+		}
+	})
+
+	t.Run("charged per batch", func(t *testing.T) {
+		ctx, err := descriptor.Admit(httptest.NewRequest(http.MethodPost, "/v1/bulk", nil), http.Header{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		persons := make([]string, 3*shortdescription.MaxBatchSize)
+		for i := range persons {
+			persons[i] = testPerson
+		}
+
+		found, limited := 0, 0
+
+		err = descriptor.BulkPersons(ctx, persons, testUserAgent, func(result shortdescription.BulkResult) error {
+			switch result.Code {
+			case "":
+				found++
+			case "rate_limited":
+				limited++
+			default:
+				t.Errorf("wanted the line %d to be found or rate limited, got %+v", result.Line, result)
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the only token of the client pays for the first batch, and no other
+		if found == 0 || found > shortdescription.MaxBatchSize || found+limited != len(persons) {
+			t.Errorf("wanted the first batch to be found and the rest rate limited, got %d found and %d rate limited", found, limited)
+		}
+	})
+}
+
+func TestBulkHandlerLimits(t *testing.T) {
+	var calls int32
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo:    testContactInfo,
+		HttpClient:     suggestUpstream(&calls, 0),
+		BulkMaxPersons: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "too many lines",
+			body: "Yoshua Bengio\nJane Doe\n\n",
+		},
+		{
+			name: "too long lines",
+			body: "Yoshua Bengio\n" + strings.Repeat("a", 5<<10) + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/bulk", strings.NewReader(tc.body))
+			req.Header.Set("User-Agent", testUserAgent)
+			req.Header.Set("Accept", "application/problem+json")
+
+			w := httptest.NewRecorder()
+			shortdescription.NewHandler(descriptor).ServeHTTP(w, req)
+
+			// refused with a status, rather than with an error line
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("wanted %v, got %v: %s", http.StatusBadRequest, w.Code, w.Body)
+			}
+
+			if contentType := w.Header().Get("Content-Type"); contentType != "application/problem+json" {
+				t.Errorf("wanted a problem response, got %q", contentType)
+			}
+
+			var problem shortdescription.ProblemDetails
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("the body is not JSON: %v", err)
+			}
+
+			if problem.Code != "invalid_argument" {
+				t.Errorf("wanted the invalid_argument code, got %+v", problem)
+			}
+		})
+	}
+
+	if calls != 0 {
+		t.Errorf("wanted nothing to be looked up, got %d upstream calls", calls)
+	}
+}
@@ -6,33 +6,76 @@ import (
 	lru "github.com/hashicorp/golang-lru/v2"
 )
 
-type cachedElement struct {
+type cachedElement[V any] struct {
 	insertion time.Time
-	value     string
+	value     V
 }
 
-type cache struct {
-	lru *lru.Cache[string, cachedElement]
-	ttl time.Duration
+type cache[V any] struct {
+	lru     *lru.Cache[string, cachedElement[V]]
+	ttl     time.Duration
+	name    string // as given to metrics
+	metrics Metrics
 }
 
-func newCache(size int, ttl time.Duration) (cache, error) {
-	c, err := lru.New[string, cachedElement](size)
-	return cache{c, ttl}, err
+func newCache[V any](name string, size int, ttl time.Duration, metrics Metrics) (cache[V], error) {
+	c, err := lru.New[string, cachedElement[V]](size)
+	return cache[V]{c, ttl, name, metrics}, err
 }
 
-func (c cache) Get(key string) (value string, ok bool) {
+func (c cache[V]) Get(key string) (value V, ok bool) {
+	value, _, ok = c.getEntry(key)
+	return value, ok
+}
+
+// getEntry also returns when the value was added.
+func (c cache[V]) getEntry(key string) (value V, insertion time.Time, ok bool) {
+	value, insertion, outcome := c.lookup(key)
+	return value, insertion, outcome == CacheHit
+}
+
+// lookup also tells whether the value was missing or expired.
+func (c cache[V]) lookup(key string) (value V, insertion time.Time, outcome CacheOutcome) {
 	elem, ok := c.lru.Get(key)
-	if !ok || time.Since(elem.insertion) >= c.ttl {
-		return "", false
+
+	switch {
+	case !ok:
+		outcome = CacheMiss
+	case time.Since(elem.insertion) >= c.ttl:
+		outcome = CacheExpired
+	default:
+		outcome = CacheHit
+		value, insertion = elem.value, elem.insertion
 	}
 
-	return elem.value, true
+	c.metrics.ObserveCacheLookup(c.name, outcome)
+
+	return value, insertion, outcome
 }
 
-func (c cache) Add(key, value string) {
-	_ = c.lru.Add(key, cachedElement{
+// Add returns when the value was added.
+func (c cache[V]) Add(key string, value V) time.Time {
+	elem := cachedElement[V]{
 		insertion: time.Now(),
 		value:     value,
-	})
+	}
+
+	if evicted := c.lru.Add(key, elem); evicted {
+		c.metrics.ObserveCacheEviction(c.name)
+	}
+
+	c.metrics.SetCacheSize(c.name, c.lru.Len())
+
+	return elem.insertion
+}
+
+// expiry returns when a value added at insertion stops being used.
+func (c cache[V]) expiry(insertion time.Time) time.Time {
+	return insertion.Add(c.ttl)
+}
+
+func (c cache[V]) Remove(key string) {
+	if c.lru.Remove(key) {
+		c.metrics.SetCacheSize(c.name, c.lru.Len())
+	}
 }
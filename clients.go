@@ -0,0 +1,179 @@
+package shortdescription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Client is a registered client of the API, identified by its API key.
+type Client struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	ContactInfo string `json:"contactInfo"`     // folded into the upstream Api-User-Agent
+	Admin       bool   `json:"admin,omitempty"` // can use the admin routes of Handler
+
+	// Rate and Burst override Config.ClientRate and Config.ClientBurst for this client.
+	Rate  float64 `json:"rate,omitempty"`
+	Burst int     `json:"burst,omitempty"`
+}
+
+// ClientRegistry looks up the registered clients by their API key. It returns ok false for
+// unknown keys.
+type ClientRegistry interface {
+	Client(ctx context.Context, key string) (client Client, ok bool, err error)
+}
+
+// FileClientRegistry reads the registered clients from a JSON file holding an array of
+// Client. Call Reload to pick up changes to the file.
+type FileClientRegistry struct {
+	path    string
+	clients atomic.Pointer[map[string]Client]
+}
+
+func NewFileClientRegistry(path string) (*FileClientRegistry, error) {
+	r := &FileClientRegistry{path: path}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload reads the file again. The previous clients are kept if it fails.
+func (r *FileClientRegistry) Reload() error {
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("cannot read the API keys file: %w", err)
+	}
+
+	var list []Client
+
+	if err := json.Unmarshal(content, &list); err != nil {
+		return fmt.Errorf("cannot parse the API keys file: %w", err)
+	}
+
+	clients := make(map[string]Client, len(list))
+
+	for i, client := range list {
+		if client.Key == "" || client.Name == "" || client.ContactInfo == "" {
+			return fmt.Errorf("client %d of the API keys file needs a key, a name and contact info", i)
+		}
+
+		if client.Rate < 0 || client.Burst < 0 {
+			return fmt.Errorf("client %q cannot have a negative quota", client.Name)
+		}
+
+		if _, ok := clients[client.Key]; ok {
+			return fmt.Errorf("client %q has a duplicated API key", client.Name)
+		}
+
+		clients[client.Key] = client
+	}
+
+	r.clients.Store(&clients)
+
+	return nil
+}
+
+func (r *FileClientRegistry) Client(_ context.Context, key string) (Client, bool, error) {
+	client, ok := (*r.clients.Load())[key]
+	return client, ok, nil
+}
+
+type clientContextKey struct{}
+
+// ContextWithClient makes the lookups done with ctx on behalf of client, whose contact info is
+// then folded into the upstream Api-User-Agent so that Wikimedia can attribute the traffic.
+func ContextWithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+func clientFromContext(ctx context.Context) (Client, bool) {
+	client, ok := ctx.Value(clientContextKey{}).(Client)
+	return client, ok
+}
+
+// apiUserAgent identifies this service to the Wikimedia APIs, along with the client the
+// request is made for, if any.
+func (d Describer) apiUserAgent(ctx context.Context) string {
+	client, ok := clientFromContext(ctx)
+	if !ok {
+		return d.userAgent
+	}
+
+	return fmt.Sprintf("%s on behalf of %s (%s)", d.userAgent, oneLine(client.Name), oneLine(client.ContactInfo))
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Admit authenticates the client of req by its API key, if any, and takes a token from its
+// rate limit, as Handler does for every request but the public ones. The RateLimit-* and
+// Retry-After headers are set on header. It returns the context of req, on behalf of the
+// client, or fails with ErrUnauthorized or ErrRateLimited. Bulk and GraphQL lookups made with
+// that context take more tokens as they go.
+//
+// Other transports, such as gRPC, can admit their requests by describing them as an
+// http.Request with the API key in the X-API-Key header and the address of the client in
+// RemoteAddr.
+func (d Describer) Admit(req *http.Request, header http.Header) (context.Context, error) {
+	client, err := d.authenticate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if client != nil {
+		req = req.WithContext(ContextWithClient(req.Context(), *client))
+	}
+
+	ctx := req.Context()
+
+	if d.clientLimiter != nil {
+		q := d.clientLimiter.quota(req, client)
+
+		if err := d.clientLimiter.allow(ctx, header, q); err != nil {
+			return nil, err
+		}
+
+		// so that the requests making many lookups, such as bulk ones, can be charged for them
+		ctx = context.WithValue(ctx, quotaContextKey{}, q)
+	}
+
+	return ctx, nil
+}
+
+// authenticate looks up the client of the API key sent with req, if any. It fails with
+// ErrUnauthorized for unknown keys, or if there's none and one is required.
+func (d Describer) authenticate(req *http.Request) (*Client, error) {
+	if d.clients == nil {
+		return nil, nil
+	}
+
+	key := apiKey(req)
+	if key == "" {
+		if d.requireAPIKey {
+			return nil, fmt.Errorf("%w: an API key is required in the %s header or the %s query parameter",
+				ErrUnauthorized, apiKeyHeader, apiKeyParam)
+		}
+
+		return nil, nil
+	}
+
+	client, ok, err := d.clients.Client(req.Context(), key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot look up the API key: %v", ErrInternal, err)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown API key", ErrUnauthorized)
+	}
+
+	return &client, nil
+}
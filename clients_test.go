@@ -0,0 +1,152 @@
+package shortdescription_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+const testClients = `[
+	{"key": "secret", "name": "Search Engine", "contactInfo": "search@example.org"},
+	{"key": "limited", "name": "Scraper", "contactInfo": "scraper@example.org", "rate": 0.01, "burst": 1}
+]`
+
+func writeClients(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.json")
+	writeClients(t, path, testClients)
+
+	registry, err := shortdescription.NewFileClientRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var apiUserAgent string
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		CachedTTL:   -1, // remove caching
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			apiUserAgent = req.Header.Get("Api-User-Agent")
+			_, _ = w.Write([]byte(testContent))
+		}),
+		Clients:       registry,
+		RequireAPIKey: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name         string
+		query        string
+		header       string
+		expectedCode int
+		expectedUA   string
+	}{
+		{
+			name:         "missing key",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "unknown key",
+			header:       "guess",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "key in header",
+			header:       "secret",
+			expectedCode: http.StatusOK,
+			expectedUA:   "on behalf of Search Engine (search@example.org)",
+		},
+		{
+			name:         "key in query",
+			query:        "&api_key=limited",
+			expectedCode: http.StatusOK,
+			expectedUA:   "on behalf of Scraper (scraper@example.org)",
+		},
+		{
+			name:         "quota of the key",
+			query:        "&api_key=limited",
+			expectedCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			apiUserAgent = ""
+
+			req := httptest.NewRequest(http.MethodGet, "/?person="+url.QueryEscape(testPerson)+tc.query, nil)
+			req.Header.Set("User-Agent", testUserAgent)
+
+			if tc.header != "" {
+				req.Header.Set("X-API-Key", tc.header)
+			}
+
+			w := httptest.NewRecorder()
+			descriptor.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("wanted %v, got %v: %s", tc.expectedCode, w.Code, w.Body)
+			}
+
+			if !strings.HasSuffix(apiUserAgent, tc.expectedUA) {
+				t.Errorf("wanted the Api-User-Agent to end with %q, got %q", tc.expectedUA, apiUserAgent)
+			}
+		})
+	}
+}
+
+func TestFileClientRegistryReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.json")
+	writeClients(t, path, testClients)
+
+	registry, err := shortdescription.NewFileClientRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeClients(t, path, `[{"key": "new", "name": "New", "contactInfo": "new@example.org"}]`)
+
+	if err := registry.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, _ := registry.Client(context.Background(), "secret"); ok {
+		t.Error("wanted the removed key to be unknown")
+	}
+
+	if client, ok, _ := registry.Client(context.Background(), "new"); !ok || client.Name != "New" {
+		t.Errorf("wanted the new key to be known, got %+v", client)
+	}
+
+	for _, invalid := range []string{
+		`not json`,
+		`[{"key": "new", "name": "New"}]`,
+		`[{"key": "a", "name": "A", "contactInfo": "a"}, {"key": "a", "name": "B", "contactInfo": "b"}]`,
+	} {
+		writeClients(t, path, invalid)
+
+		if err := registry.Reload(); err == nil {
+			t.Errorf("wanted %s to be refused", invalid)
+		}
+	}
+
+	if _, ok, _ := registry.Client(context.Background(), "new"); !ok {
+		t.Error("wanted the previous keys to be kept after a failed reload")
+	}
+}
@@ -1,21 +1,92 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 
 	shortdescription "github.com/Inuart/wikimedia-exercise"
+	"github.com/Inuart/wikimedia-exercise/grpcapi"
+	"github.com/Inuart/wikimedia-exercise/prommetrics"
 )
 
 type Config struct {
 	Addr        string        `envconfig:"ADDR"`
+	GRPCAddr    string        `envconfig:"GRPC_ADDR"` // Where to serve the gRPC API, if anywhere
 	ContactInfo string        `envconfig:"CONTACT_INFO" required:"true"`
 	CacheSize   int           `envconfig:"CACHE_SIZE"`        // Max amount of results the cache should hold
 	CachedTTL   time.Duration `envconfig:"CACHED_RESULT_TTL"` // Time To Live for each cached result
+	Backend     string        `envconfig:"BACKEND"`           // Upstream API to fetch descriptions from
+	DetailsTTL  time.Duration `envconfig:"DETAILS_TTL"`       // Time To Live for each cached optional field
+
+	ScanLimitBytes int64 `envconfig:"SCAN_LIMIT_BYTES"` // Bytes of wikitext to read before giving up on a description
+	ScanLimitLines int   `envconfig:"SCAN_LIMIT_LINES"` // Lines of wikitext to read before giving up on a description
+
+	UpstreamRate   float64 `envconfig:"UPSTREAM_RATE"`   // Requests per second to the Wikimedia APIs
+	UpstreamBurst  int     `envconfig:"UPSTREAM_BURST"`  // Requests that can be made at once
+	UpstreamPolicy string  `envconfig:"UPSTREAM_POLICY"` // "queue" or "shed" requests over the rate
+	UpstreamQueue  int     `envconfig:"UPSTREAM_QUEUE"`  // Max requests waiting for their turn
+	MaxLag         int     `envconfig:"MAX_LAG"`         // Seconds of database lag the API may have
+
+	ClientRate      float64 `envconfig:"CLIENT_RATE"`      // Requests per second each client can make
+	ClientBurst     int     `envconfig:"CLIENT_BURST"`     // Requests each client can make at once
+	ClientKey       string  `envconfig:"CLIENT_KEY"`       // "ip" or "api-key"
+	ForwardedHeader string  `envconfig:"FORWARDED_HEADER"` // Trusted header with the client IP, such as X-Forwarded-For
+
+	APIKeysFile   string `envconfig:"API_KEYS_FILE"`   // JSON file with the registered clients, reloaded on SIGHUP
+	RequireAPIKey bool   `envconfig:"REQUIRE_API_KEY"` // Refuse requests without a registered API key
+
+	CORSAllowedOrigins   []string      `envconfig:"CORS_ALLOWED_ORIGINS"`   // Comma-separated origins, such as https://*.example.org
+	CORSAllowedMethods   []string      `envconfig:"CORS_ALLOWED_METHODS"`   // Comma-separated methods
+	CORSAllowedHeaders   []string      `envconfig:"CORS_ALLOWED_HEADERS"`   // Comma-separated request headers
+	CORSAllowCredentials bool          `envconfig:"CORS_ALLOW_CREDENTIALS"` // Allow cookies and credentials
+	CORSMaxAge           time.Duration `envconfig:"CORS_MAX_AGE"`           // How long browsers can cache preflight responses
+
+	ProblemJSON bool `envconfig:"PROBLEM_JSON"` // Answer errors with RFC 7807 problem details
+
+	BulkConcurrency int `envconfig:"BULK_CONCURRENCY"` // Upstream batches each bulk request looks up at once
+	BulkMaxPersons  int `envconfig:"BULK_MAX_PERSONS"` // Most lines a bulk request can have
+
+	GraphQLMaxDepth      int `envconfig:"GRAPHQL_MAX_DEPTH"`      // Deepest GraphQL query accepted
+	GraphQLMaxComplexity int `envconfig:"GRAPHQL_MAX_COMPLEXITY"` // Most fields a GraphQL query can select
+
+	SubscriptionRefresh        time.Duration `envconfig:"SUBSCRIPTION_REFRESH"`         // How often subscribed persons are looked up again
+	SubscriptionRefreshTimeout time.Duration `envconfig:"SUBSCRIPTION_REFRESH_TIMEOUT"` // How long each of those look ups can take
+	SubscriptionMaxPersons     int           `envconfig:"SUBSCRIPTION_MAX_PERSONS"`     // Most persons a subscription can follow
+	MaxSubscriptions           int           `envconfig:"MAX_SUBSCRIPTIONS"`            // Most subscriptions open at once
+
+	WarmUp        []string      `envconfig:"WARM_UP"`        // Comma-separated persons to cache before being ready
+	ProbePerson   string        `envconfig:"PROBE_PERSON"`   // Person looked up to check the upstream API is ready
+	ProbeInterval time.Duration `envconfig:"PROBE_INTERVAL"` // How long the result of the probe is kept
+
+	SuggestCacheSize int           `envconfig:"SUGGEST_CACHE_SIZE"` // Max amount of prefixes the suggest cache should hold
+	SuggestTTL       time.Duration `envconfig:"SUGGEST_TTL"`        // Time To Live for each cached prefix
+	SuggestTimeout   time.Duration `envconfig:"SUGGEST_TIMEOUT"`    // Latency budget for each suggestion
+
+	LogLevel        string  `envconfig:"LOG_LEVEL" default:"info"` // debug, info, warn or error
+	AccessLogSample float64 `envconfig:"ACCESS_LOG_SAMPLE"`        // Fraction of the successful requests logged
+
+	TracingExporter string `envconfig:"TRACING_EXPORTER"`                       // none, otlp or stdout
+	OTLPEndpoint    string `envconfig:"OTLP_ENDPOINT" default:"localhost:4318"` // host:port of the OTLP/HTTP collector
 }
 
 func main() {
@@ -26,24 +97,276 @@ func main() {
 		log.Fatal("error parsing env vars:", err)
 	}
 
+	level, err := shortdescription.ParseLogLevel(conf.LogLevel)
+	if err != nil {
+		log.Fatal("error parsing env vars:", err)
+	}
+
+	logger, err := shortdescription.NewJSONLogger(os.Stderr, level, conf.AccessLogSample)
+	if err != nil {
+		log.Fatal("error parsing env vars:", err)
+	}
+
+	clientKey := shortdescription.ClientIP
+	if conf.ForwardedHeader != "" {
+		clientKey = shortdescription.ClientForwardedFor(conf.ForwardedHeader)
+	}
+
+	switch conf.ClientKey {
+	case "", "ip":
+	case "api-key":
+		clientKey = shortdescription.ClientAPIKey(clientKey)
+	default:
+		fatal(logger, "unknown CLIENT_KEY", fmt.Errorf("%q is not ip nor api-key", conf.ClientKey))
+	}
+
+	var clients shortdescription.ClientRegistry
+
+	if conf.APIKeysFile != "" {
+		registry, err := shortdescription.NewFileClientRegistry(conf.APIKeysFile)
+		if err != nil {
+			fatal(logger, "cannot read the API keys", err)
+		}
+
+		go reloadOnHangup(logger, registry)
+
+		clients = registry
+	}
+
+	metrics, err := prommetrics.New(prometheus.DefaultRegisterer)
+	if err != nil {
+		fatal(logger, "cannot register the metrics", err)
+	}
+
+	tracerProvider, shutdownTracing, err := newTracerProvider(conf)
+	if err != nil {
+		fatal(logger, "cannot export the traces", err)
+	}
+
 	descriptor, err := shortdescription.New(shortdescription.Config{
 		ContactInfo: conf.ContactInfo,
 		CacheSize:   conf.CacheSize,
 		CachedTTL:   conf.CachedTTL,
+		Backend:     shortdescription.Backend(conf.Backend),
+		DetailsTTL:  conf.DetailsTTL,
+		Metrics:     metrics,
+
+		AccessLogger: logger,
+
+		TracerProvider: tracerProvider,
+		Propagator:     propagation.TraceContext{},
+
+		ScanLimitBytes: conf.ScanLimitBytes,
+		ScanLimitLines: conf.ScanLimitLines,
+
+		UpstreamRate:   conf.UpstreamRate,
+		UpstreamBurst:  conf.UpstreamBurst,
+		UpstreamPolicy: shortdescription.LimitPolicy(conf.UpstreamPolicy),
+		UpstreamQueue:  conf.UpstreamQueue,
+		MaxLag:         conf.MaxLag,
+
+		ClientRate:  conf.ClientRate,
+		ClientBurst: conf.ClientBurst,
+		ClientKey:   clientKey,
+
+		Clients:       clients,
+		RequireAPIKey: conf.RequireAPIKey,
+
+		CORS: shortdescription.CORS{
+			AllowedOrigins:   conf.CORSAllowedOrigins,
+			AllowedMethods:   conf.CORSAllowedMethods,
+			AllowedHeaders:   conf.CORSAllowedHeaders,
+			AllowCredentials: conf.CORSAllowCredentials,
+			MaxAge:           conf.CORSMaxAge,
+		},
+		ProblemJSON: conf.ProblemJSON,
+
+		BulkConcurrency: conf.BulkConcurrency,
+		BulkMaxPersons:  conf.BulkMaxPersons,
+
+		GraphQLMaxDepth:      conf.GraphQLMaxDepth,
+		GraphQLMaxComplexity: conf.GraphQLMaxComplexity,
+
+		SubscriptionRefresh:        conf.SubscriptionRefresh,
+		SubscriptionRefreshTimeout: conf.SubscriptionRefreshTimeout,
+		SubscriptionMaxPersons:     conf.SubscriptionMaxPersons,
+		MaxSubscriptions:           conf.MaxSubscriptions,
+
+		WarmUp:        conf.WarmUp,
+		ProbePerson:   conf.ProbePerson,
+		ProbeInterval: conf.ProbeInterval,
+
+		SuggestCacheSize: conf.SuggestCacheSize,
+		SuggestTTL:       conf.SuggestTTL,
+		SuggestTimeout:   conf.SuggestTimeout,
 	})
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "cannot create the describer", err)
+	}
+
+	// either server stopping on its own stops the process
+	stopped := make(chan error, 2)
+
+	var grpcServer *grpc.Server
+	if conf.GRPCAddr != "" {
+		grpcServer = serveGRPC(logger, conf.GRPCAddr, descriptor, stopped)
 	}
 
 	listener, err := net.Listen("tcp", conf.Addr)
 	if err != nil {
-		log.Fatalf("Unable to listen to the provided address %q: %v", conf.Addr, err)
+		fatal(logger, "unable to listen to the provided address "+conf.Addr, err)
 	}
 
-	log.Println("The shortdescription server will listen at", listener.Addr().String())
+	logger.Log(shortdescription.LevelInfo, "the shortdescription server will listen", map[string]any{"addr": listener.Addr().String()})
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/", shortdescription.NewHandler(descriptor))
+
+	server := &http.Server{Handler: mux}
+
+	go func() { stopped <- server.Serve(listener) }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case err := <-stopped:
+		flushTraces(logger, shutdownTracing)
+		fatal(logger, "the server stopped", err)
+	case <-ctx.Done():
+	}
+
+	logger.Log(shortdescription.LevelInfo, "the shortdescription server is shutting down", nil)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	if grpcServer != nil {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			stopGRPC(shutdownCtx, grpcServer)
+		}()
+	}
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		// streams, such as subscriptions, go on until their clients leave
+		_ = server.Close()
+	}
+
+	wg.Wait()
+
+	flushTraces(logger, shutdownTracing)
+}
+
+// shutdownTimeout is how long the requests being served get to complete, and the batched
+// spans to be exported, when the server is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
+// newTracerProvider exports the traces as configured, or doesn't trace at all. The returned
+// shutdown exports the spans that are still batched.
+func newTracerProvider(conf Config) (provider trace.TracerProvider, shutdown func(context.Context) error, err error) {
+	var exporter sdktrace.SpanExporter
+
+	switch conf.TracingExporter {
+	case "", "none":
+		return trace.NewNoopTracerProvider(), func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(context.Background(),
+			otlptracehttp.WithEndpoint(conf.OTLPEndpoint),
+			otlptracehttp.WithInsecure(),
+		)
+	case "stdout":
+		exporter, err = stdouttrace.New()
+	default:
+		return nil, nil, fmt.Errorf("TRACING_EXPORTER %q is not none, otlp nor stdout", conf.TracingExporter)
+	}
 
-	err = http.Serve(listener, descriptor)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
+	}
+
+	sdkProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("shortdescription"))),
+	)
+
+	return sdkProvider, sdkProvider.Shutdown, nil
+}
+
+// flushTraces exports the spans that are still batched, before the process exits.
+func flushTraces(logger *shortdescription.JSONLogger, shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := shutdown(ctx); err != nil {
+		logger.Log(shortdescription.LevelWarn, "cannot export the last traces", map[string]any{"error": err.Error()})
+	}
+}
+
+// fatal logs err and exits.
+func fatal(logger *shortdescription.JSONLogger, msg string, err error) {
+	logger.Log(shortdescription.LevelError, msg, map[string]any{"error": err.Error()})
+	os.Exit(1)
+}
+
+// serveGRPC serves the gRPC API of descriptor at addr in the background, until the returned
+// server is stopped. If it stops on its own, the reason is sent to stopped.
+func serveGRPC(logger *shortdescription.JSONLogger, addr string, descriptor shortdescription.Describer, stopped chan<- error) *grpc.Server {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		fatal(logger, "unable to listen to the provided gRPC address "+addr, err)
+	}
+
+	logger.Log(shortdescription.LevelInfo, "the shortdescription gRPC server will listen", map[string]any{"addr": listener.Addr().String()})
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcapi.UnaryInterceptor(descriptor)),
+		grpc.ChainStreamInterceptor(grpcapi.StreamInterceptor(descriptor)),
+	)
+	grpcapi.Register(server, descriptor)
+
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			stopped <- fmt.Errorf("gRPC: %w", err)
+		}
+	}()
+
+	return server
+}
+
+// stopGRPC lets the calls being served complete, or cancels them once ctx is done.
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	done := make(chan struct{})
+
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// streams, such as subscriptions, go on until their clients leave
+		server.Stop()
+	}
+}
+
+// reloadOnHangup reloads the API keys file whenever the process receives a SIGHUP.
+func reloadOnHangup(logger *shortdescription.JSONLogger, registry *shortdescription.FileClientRegistry) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	for range hangup {
+		if err := registry.Reload(); err != nil {
+			logger.Log(shortdescription.LevelWarn, "keeping the previous API keys", map[string]any{"error": err.Error()})
+			continue
+		}
+
+		logger.Log(shortdescription.LevelInfo, "API keys reloaded", nil)
 	}
 }
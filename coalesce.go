@@ -0,0 +1,88 @@
+package shortdescription
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// flightGroup coalesces the identical lookups made at the same time into a single one, whose
+// result is shared by all of them. Only the first of them makes the upstream requests, on
+// behalf of its own client.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+	waiting *gauge // lookups waiting for another one
+}
+
+// flight is a lookup in progress. Its results are only read once done is closed.
+type flight struct {
+	done    chan struct{}
+	descr   description
+	fetched time.Time
+	err     error
+
+	// cancelled is set when the lookup failed because its own caller went away, in which
+	// case the ones waiting for it try again instead of sharing the failure.
+	cancelled bool
+}
+
+func newFlightGroup(metrics Metrics) *flightGroup {
+	return &flightGroup{
+		flights: make(map[string]*flight),
+		waiting: newGauge(metrics.SetCoalescedLookups),
+	}
+}
+
+// do calls lookup, unless one with the same key is already in flight, in which case its
+// result is waited for instead.
+func (g *flightGroup) do(ctx context.Context, key string, lookup func() (description, time.Time, error)) (description, time.Time, error) {
+	g.mu.Lock()
+
+	for {
+		f, ok := g.flights[key]
+		if !ok {
+			break
+		}
+
+		g.mu.Unlock()
+
+		if err := g.wait(ctx, f); err != nil {
+			return description{}, time.Time{}, err
+		}
+
+		if !f.cancelled {
+			return f.descr, f.fetched, f.err
+		}
+
+		g.mu.Lock()
+	}
+
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	f.descr, f.fetched, f.err = lookup()
+	f.cancelled = f.err != nil && ctx.Err() != nil
+
+	g.mu.Lock()
+	delete(g.flights, key)
+	g.mu.Unlock()
+
+	close(f.done)
+
+	return f.descr, f.fetched, f.err
+}
+
+// wait waits for f to be done, unless ctx is done first.
+func (g *flightGroup) wait(ctx context.Context, f *flight) error {
+	g.waiting.add(1)
+	defer g.waiting.add(-1)
+
+	select {
+	case <-f.done:
+		return nil
+	case <-ctx.Done():
+		return fetchError(ctx.Err())
+	}
+}
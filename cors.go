@@ -0,0 +1,197 @@
+package shortdescription
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS is the Cross-Origin Resource Sharing policy of ServeHTTP, telling which websites can
+// call the API from their visitors' browsers.
+type CORS struct {
+	// AllowedOrigins are exact origins, such as "https://example.org", or wildcard subdomains,
+	// such as "https://*.example.org". "*" allows any origin. Defaults to "*".
+	AllowedOrigins []string
+	AllowedMethods []string      // defaults to GET and POST, for GraphQL and bulk lookups
+	AllowedHeaders []string      // defaults to X-API-Key, Content-Type and X-Request-ID
+	MaxAge         time.Duration // how long browsers can cache preflight responses
+
+	// AllowCredentials needs the origins to be listed, as with "*" any website could call the
+	// API on behalf of its visitors.
+	AllowCredentials bool
+}
+
+// exposedHeaders are the response headers that scripts can read, besides the safelisted ones.
+var exposedHeaders = []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-Request-ID"}
+
+type corsPolicy struct {
+	anyOrigin   bool
+	origins     map[string]bool
+	wildcards   [][2]string // prefix and suffix around the "*"
+	methods     map[string]bool
+	headers     map[string]bool
+	credentials bool
+
+	allowMethods string
+	allowHeaders string
+	maxAge       string
+}
+
+func newCORSPolicy(cfg CORS) (corsPolicy, error) {
+	if len(cfg.AllowedOrigins) == 0 {
+		cfg.AllowedOrigins = []string{"*"}
+	}
+
+	if len(cfg.AllowedMethods) == 0 {
+		cfg.AllowedMethods = []string{http.MethodGet, http.MethodPost}
+	}
+
+	if len(cfg.AllowedHeaders) == 0 {
+		// JSON bodies, as sent to /graphql, need Content-Type to be allowed
+		cfg.AllowedHeaders = []string{apiKeyHeader, "Content-Type", requestIDHeader}
+	}
+
+	if cfg.MaxAge < 0 {
+		return corsPolicy{}, fmt.Errorf("CORS max age cannot be negative")
+	}
+
+	p := corsPolicy{
+		origins:     map[string]bool{},
+		methods:     map[string]bool{},
+		headers:     map[string]bool{},
+		credentials: cfg.AllowCredentials,
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+
+		switch {
+		case origin == "*":
+			if cfg.AllowCredentials {
+				return corsPolicy{}, fmt.Errorf("CORS credentials cannot be allowed for any origin")
+			}
+
+			p.anyOrigin = true
+		case strings.Contains(origin, "*"):
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			if !strings.HasSuffix(prefix, "://") || !strings.HasPrefix(suffix, ".") || strings.Contains(suffix, "*") {
+				return corsPolicy{}, fmt.Errorf("CORS origin %q must look like https://*.example.org", origin)
+			}
+
+			p.wildcards = append(p.wildcards, [2]string{prefix, suffix})
+		default:
+			p.origins[origin] = true
+		}
+	}
+
+	methods := make([]string, len(cfg.AllowedMethods))
+	for i, method := range cfg.AllowedMethods {
+		methods[i] = strings.ToUpper(strings.TrimSpace(method))
+		p.methods[methods[i]] = true
+	}
+
+	headers := make([]string, len(cfg.AllowedHeaders))
+	for i, header := range cfg.AllowedHeaders {
+		headers[i] = http.CanonicalHeaderKey(strings.TrimSpace(header))
+		p.headers[headers[i]] = true
+	}
+
+	p.allowMethods = strings.Join(methods, ", ")
+	p.allowHeaders = strings.Join(headers, ", ")
+
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	return p, nil
+}
+
+func (p corsPolicy) allowedOrigin(origin string) bool {
+	if p.anyOrigin || p.origins[origin] {
+		return true
+	}
+
+	for _, wildcard := range p.wildcards {
+		prefix, suffix := wildcard[0], wildcard[1]
+
+		if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+			!strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handle sets the CORS headers of the response, and answers preflight requests. It returns
+// true if the request has been answered.
+func (p corsPolicy) handle(w http.ResponseWriter, req *http.Request) bool {
+	header := w.Header()
+	origin := req.Header.Get("Origin")
+	preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+
+	if !p.anyOrigin || p.credentials {
+		header.Add("Vary", "Origin")
+	}
+
+	if preflight {
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if origin == "" || !p.allowedOrigin(strings.ToLower(origin)) || (preflight && !p.allowedPreflight(req)) {
+		if req.Method == http.MethodOptions {
+			header.Set("Allow", p.allowMethods+", "+http.MethodOptions)
+			w.WriteHeader(http.StatusNoContent)
+
+			return true
+		}
+
+		return false
+	}
+
+	if p.anyOrigin && !p.credentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if p.credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if req.Method != http.MethodOptions {
+		header.Set("Access-Control-Expose-Headers", strings.Join(exposedHeaders, ", "))
+		return false
+	}
+
+	header.Set("Access-Control-Allow-Methods", p.allowMethods)
+	header.Set("Access-Control-Allow-Headers", p.allowHeaders)
+
+	if p.maxAge != "" {
+		header.Set("Access-Control-Max-Age", p.maxAge)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return true
+}
+
+// allowedPreflight tells whether the method and headers of a preflight request are allowed.
+func (p corsPolicy) allowedPreflight(req *http.Request) bool {
+	if !p.methods[strings.ToUpper(req.Header.Get("Access-Control-Request-Method"))] {
+		return false
+	}
+
+	for _, requested := range strings.Split(req.Header.Get("Access-Control-Request-Headers"), ",") {
+		requested = strings.TrimSpace(requested)
+
+		if requested != "" && !p.headers[http.CanonicalHeaderKey(requested)] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,184 @@
+package shortdescription_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+func TestCORS(t *testing.T) {
+	restricted := shortdescription.CORS{
+		AllowedOrigins:   []string{"https://example.org", "https://*.wikipedia.org"},
+		AllowedHeaders:   []string{"X-API-Key", "Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+
+	testCases := []struct {
+		name            string
+		cors            shortdescription.CORS
+		method          string
+		header          http.Header
+		expectedCode    int
+		expectedHeaders map[string]string
+	}{
+		{
+			name:         "any origin by default",
+			method:       http.MethodGet,
+			header:       http.Header{"Origin": {"https://anywhere.example"}},
+			expectedCode: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":   "*",
+				"Access-Control-Expose-Headers": "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After, X-Request-ID",
+			},
+		},
+		{
+			name:         "exact origin",
+			cors:         restricted,
+			method:       http.MethodGet,
+			header:       http.Header{"Origin": {"https://example.org"}},
+			expectedCode: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://example.org",
+				"Access-Control-Allow-Credentials": "true",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:         "wildcard subdomain",
+			cors:         restricted,
+			method:       http.MethodGet,
+			header:       http.Header{"Origin": {"https://en.m.wikipedia.org"}},
+			expectedCode: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "https://en.m.wikipedia.org",
+			},
+		},
+		{
+			name:         "wildcard doesn't match the bare domain nor lookalikes",
+			cors:         restricted,
+			method:       http.MethodGet,
+			header:       http.Header{"Origin": {"https://evilwikipedia.org"}},
+			expectedCode: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:   "preflight",
+			cors:   restricted,
+			method: http.MethodOptions,
+			header: http.Header{
+				"Origin":                         {"https://example.org"},
+				"Access-Control-Request-Method":  {"GET"},
+				"Access-Control-Request-Headers": {"x-api-key"},
+			},
+			expectedCode: http.StatusNoContent,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://example.org",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "X-Api-Key, Content-Type",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:   "preflight of a JSON POST by default",
+			method: http.MethodOptions,
+			header: http.Header{
+				"Origin":                         {"https://anywhere.example"},
+				"Access-Control-Request-Method":  {"POST"},
+				"Access-Control-Request-Headers": {"content-type,x-request-id"},
+			},
+			expectedCode: http.StatusNoContent,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "X-Api-Key, Content-Type, X-Request-Id",
+			},
+		},
+		{
+			name:   "preflight with a method not allowed",
+			cors:   restricted,
+			method: http.MethodOptions,
+			header: http.Header{
+				"Origin":                        {"https://example.org"},
+				"Access-Control-Request-Method": {"DELETE"},
+			},
+			expectedCode: http.StatusNoContent,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+		{
+			name:   "preflight with a header not allowed",
+			cors:   restricted,
+			method: http.MethodOptions,
+			header: http.Header{
+				"Origin":                         {"https://example.org"},
+				"Access-Control-Request-Method":  {"GET"},
+				"Access-Control-Request-Headers": {"X-Secret"},
+			},
+			expectedCode: http.StatusNoContent,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			descriptor, err := shortdescription.New(shortdescription.Config{
+				ContactInfo: testContactInfo,
+				HttpClient:  &mockHttpClient{},
+				CORS:        tc.cors,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(tc.method, "/?person="+url.QueryEscape(testPerson), nil)
+			req.Header = tc.header
+			req.Header.Set("User-Agent", testUserAgent)
+
+			w := httptest.NewRecorder()
+			descriptor.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("wanted %v, got %v: %s", tc.expectedCode, w.Code, w.Body)
+			}
+
+			for name, value := range tc.expectedHeaders {
+				if got := w.Header().Get(name); got != value {
+					t.Errorf("wanted %s to be %q, got %q", name, value, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewCORS(t *testing.T) {
+	for _, origin := range []string{"*.example.org", "https://www.*.org", "https://*example.org"} {
+		_, err := shortdescription.New(shortdescription.Config{
+			ContactInfo: testContactInfo,
+			CORS:        shortdescription.CORS{AllowedOrigins: []string{origin}},
+		})
+		if err == nil {
+			t.Errorf("wanted %q to be refused", origin)
+		}
+	}
+
+	for _, origins := range [][]string{{"*"}, nil} {
+		_, err := shortdescription.New(shortdescription.Config{
+			ContactInfo: testContactInfo,
+			CORS:        shortdescription.CORS{AllowedOrigins: origins, AllowCredentials: true},
+		})
+		if err == nil {
+			t.Errorf("wanted credentials to be refused for the origins %q", origins)
+		}
+	}
+}
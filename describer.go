@@ -4,10 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/graphql-go/graphql"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Config struct {
@@ -15,11 +24,105 @@ type Config struct {
 	CacheSize   int           // defaults to DefaultCacheSize
 	CachedTTL   time.Duration // defaults to DefaultCachedTTl
 	HttpClient  HttpDoer
+	Backend     Backend       // defaults to BackendWikitext
+	DetailsTTL  time.Duration // defaults to DefaultDetailsTTL. See WithFields.
+	Metrics     Metrics       // defaults to no metrics
+
+	AccessLogger AccessLogger // of the requests to ServeHTTP, defaults to none
+
+	// Trace ServeHTTP, the lookups, the cache and the requests made to the Wikimedia APIs,
+	// continuing the traces whose context comes in the requests to ServeHTTP.
+	TracerProvider trace.TracerProvider          // defaults to no tracing
+	Propagator     propagation.TextMapPropagator // defaults to W3C Trace Context
+
+	// Stop looking for a short description in the wikitext after reading this many bytes
+	// or lines of it, and report it as not found. 0 means no limit.
+	ScanLimitBytes int64
+	ScanLimitLines int
+
+	// Pace the requests made to the Wikimedia APIs with a token bucket of UpstreamRate
+	// requests per second. 0 means no limit.
+	UpstreamRate   float64
+	UpstreamBurst  int         // defaults to 1
+	UpstreamPolicy LimitPolicy // defaults to LimitQueue
+	UpstreamQueue  int         // max requests waiting for their turn, defaults to DefaultUpstreamQueue
+	MaxLag         int         // seconds of database lag the API may have, defaults to DefaultMaxLag
+
+	// Limit the requests each client can make to ServeHTTP to ClientRate per second.
+	// 0 means no limit.
+	ClientRate  float64
+	ClientBurst int            // defaults to a second worth of requests
+	ClientKey   ClientKey      // defaults to ClientIP
+	ClientStore RateLimitStore // defaults to a MemoryRateLimitStore of DefaultClientStoreSize clients
+
+	// Validate the API keys sent to ServeHTTP against Clients, which are then limited by key
+	// with their own quota. Requests without a key are only accepted if RequireAPIKey is false.
+	Clients       ClientRegistry
+	RequireAPIKey bool
+
+	CORS CORS // defaults to allowing any website to GET
+
+	// Answer errors with RFC 7807 problem details instead of the ErrorResponse envelope.
+	// Clients can also ask for them with "Accept: application/problem+json".
+	ProblemJSON bool
+
+	BulkConcurrency int // upstream batches each bulk request looks up at once, defaults to DefaultBulkConcurrency
+	BulkMaxPersons  int // lines each bulk request can have, defaults to DefaultBulkMaxPersons
+
+	// Subscribers get the short descriptions of up to SubscriptionMaxPersons persons each,
+	// which are looked up again every SubscriptionRefresh. See Describer.Subscribe.
+	SubscriptionRefresh        time.Duration // defaults to CachedTTL, or DefaultSubscriptionRefresh if nothing is cached
+	SubscriptionRefreshTimeout time.Duration // for each look up, defaults to DefaultSubscriptionRefreshTimeout
+	SubscriptionMaxPersons     int           // defaults to MaxBatchSize
+	MaxSubscriptions           int           // defaults to DefaultMaxSubscriptions
+
+	// Refuse GraphQL queries nested deeper than GraphQLMaxDepth fields, or selecting more
+	// than GraphQLMaxComplexity fields, counting those of people once per title.
+	GraphQLMaxDepth      int // defaults to DefaultGraphQLMaxDepth
+	GraphQLMaxComplexity int // defaults to DefaultGraphQLMaxComplexity
+
+	// Look the WarmUp persons up in the background as soon as the Describer is created, and
+	// tell it's not ready until they are. See Describer.Readiness.
+	WarmUp []string
+
+	// Check that the upstream API answers by looking ProbePerson up, at most every
+	// ProbeInterval, while checking readiness. No probe is made when it's empty.
+	ProbePerson   string
+	ProbeInterval time.Duration // defaults to DefaultProbeInterval
+
+	SuggestCacheSize int           // defaults to DefaultSuggestCacheSize
+	SuggestTTL       time.Duration // defaults to DefaultSuggestTTL
+	SuggestTimeout   time.Duration // defaults to DefaultSuggestTimeout
+	SuggestLimit     int           // defaults to DefaultSuggestLimit
 }
 
 const (
 	DefaultCacheSize = 500
 	DefaultCachedTTl = time.Hour
+
+	DefaultDetailsTTL = 24 * time.Hour
+
+	DefaultUpstreamQueue = 100
+	DefaultMaxLag        = 5
+
+	DefaultClientStoreSize = 10000
+
+	DefaultBulkConcurrency = 4
+	DefaultBulkMaxPersons  = 10000
+
+	DefaultMaxSubscriptions           = 1000
+	DefaultSubscriptionRefresh        = time.Minute
+	DefaultSubscriptionRefreshTimeout = 30 * time.Second
+
+	DefaultGraphQLMaxDepth      = 12 // enough for the introspection query of GraphiQL
+	DefaultGraphQLMaxComplexity = 1000
+
+	DefaultProbeInterval = time.Minute
+
+	DefaultSuggestCacheSize = 500
+	DefaultSuggestTTL       = 10 * time.Minute
+	DefaultSuggestTimeout   = time.Second
+	DefaultSuggestLimit     = 10
 )
 
 type HttpDoer interface {
@@ -48,84 +151,569 @@ func New(cfg Config) (Describer, error) {
 		cfg.HttpClient = http.DefaultClient
 	}
 
-	cache, err := newCache(cfg.CacheSize, cfg.CachedTTL)
+	if cfg.Backend == "" {
+		cfg.Backend = BackendWikitext
+	}
+
+	if cfg.DetailsTTL == 0 {
+		cfg.DetailsTTL = DefaultDetailsTTL
+	}
+
+	if cfg.Metrics == nil {
+		cfg.Metrics = NoMetrics{}
+	}
+
+	if cfg.TracerProvider == nil {
+		cfg.TracerProvider = trace.NewNoopTracerProvider()
+	}
+
+	if cfg.Propagator == nil {
+		cfg.Propagator = propagation.TraceContext{}
+	}
+
+	if cfg.ScanLimitBytes < 0 || cfg.ScanLimitLines < 0 {
+		return Describer{}, errors.New("shortdescription.New: scan limits cannot be negative")
+	}
+
+	if cfg.UpstreamRate < 0 || cfg.UpstreamBurst < 0 || cfg.UpstreamQueue < 0 || cfg.MaxLag < 0 {
+		return Describer{}, errors.New("shortdescription.New: upstream limits cannot be negative")
+	}
+
+	if cfg.UpstreamBurst == 0 {
+		cfg.UpstreamBurst = 1
+	}
+
+	if cfg.UpstreamPolicy == "" {
+		cfg.UpstreamPolicy = LimitQueue
+	}
+
+	if cfg.UpstreamPolicy != LimitQueue && cfg.UpstreamPolicy != LimitShed {
+		return Describer{}, fmt.Errorf("shortdescription.New: unknown upstream policy %q", cfg.UpstreamPolicy)
+	}
+
+	if cfg.UpstreamQueue == 0 {
+		cfg.UpstreamQueue = DefaultUpstreamQueue
+	}
+
+	if cfg.MaxLag == 0 {
+		cfg.MaxLag = DefaultMaxLag
+	}
+
+	if cfg.ClientRate < 0 || cfg.ClientBurst < 0 {
+		return Describer{}, errors.New("shortdescription.New: client limits cannot be negative")
+	}
+
+	cors, err := newCORSPolicy(cfg.CORS)
+	if err != nil {
+		return Describer{}, fmt.Errorf("shortdescription.New: %w", err)
+	}
+
+	if cfg.RequireAPIKey && cfg.Clients == nil {
+		return Describer{}, errors.New("shortdescription.New: RequireAPIKey needs a Clients registry")
+	}
+
+	var limiter *clientLimiter
+
+	if cfg.ClientRate > 0 || cfg.Clients != nil {
+		if cfg.ClientBurst == 0 {
+			cfg.ClientBurst = int(math.Ceil(cfg.ClientRate))
+		}
+
+		if cfg.ClientKey == nil {
+			cfg.ClientKey = ClientIP
+		}
+
+		if cfg.ClientStore == nil {
+			store, err := NewMemoryRateLimitStore(DefaultClientStoreSize)
+			if err != nil {
+				return Describer{}, fmt.Errorf("rate limit store creation failed: %w", err)
+			}
+
+			cfg.ClientStore = store
+		}
+
+		limiter = &clientLimiter{
+			rate:  cfg.ClientRate,
+			burst: cfg.ClientBurst,
+			key:   cfg.ClientKey,
+			store: cfg.ClientStore,
+		}
+	}
+
+	if cfg.BulkConcurrency < 0 {
+		return Describer{}, errors.New("shortdescription.New: BulkConcurrency cannot be negative")
+	}
+
+	if cfg.BulkConcurrency == 0 {
+		cfg.BulkConcurrency = DefaultBulkConcurrency
+	}
+
+	if cfg.BulkMaxPersons < 0 {
+		return Describer{}, errors.New("shortdescription.New: BulkMaxPersons cannot be negative")
+	}
+
+	if cfg.BulkMaxPersons == 0 {
+		cfg.BulkMaxPersons = DefaultBulkMaxPersons
+	}
+
+	if cfg.SubscriptionRefresh < 0 || cfg.SubscriptionRefreshTimeout < 0 || cfg.SubscriptionMaxPersons < 0 ||
+		cfg.MaxSubscriptions < 0 {
+		return Describer{}, errors.New("shortdescription.New: subscription limits cannot be negative")
+	}
+
+	if cfg.SubscriptionRefresh == 0 {
+		cfg.SubscriptionRefresh = cfg.CachedTTL
+	}
+
+	if cfg.SubscriptionRefresh <= 0 {
+		cfg.SubscriptionRefresh = DefaultSubscriptionRefresh
+	}
+
+	if cfg.SubscriptionRefreshTimeout == 0 {
+		cfg.SubscriptionRefreshTimeout = DefaultSubscriptionRefreshTimeout
+	}
+
+	if cfg.SubscriptionMaxPersons == 0 {
+		cfg.SubscriptionMaxPersons = MaxBatchSize
+	}
+
+	if cfg.MaxSubscriptions == 0 {
+		cfg.MaxSubscriptions = DefaultMaxSubscriptions
+	}
+
+	if cfg.GraphQLMaxDepth < 0 || cfg.GraphQLMaxComplexity < 0 {
+		return Describer{}, errors.New("shortdescription.New: GraphQL limits cannot be negative")
+	}
+
+	if cfg.GraphQLMaxDepth == 0 {
+		cfg.GraphQLMaxDepth = DefaultGraphQLMaxDepth
+	}
+
+	if cfg.GraphQLMaxComplexity == 0 {
+		cfg.GraphQLMaxComplexity = DefaultGraphQLMaxComplexity
+	}
+
+	warmUp := make([]string, 0, len(cfg.WarmUp))
+	warmingUp := make(map[string]bool, len(cfg.WarmUp))
+
+	for _, person := range cfg.WarmUp {
+		if person == "" || strings.Contains(person, "|") {
+			return Describer{}, fmt.Errorf("shortdescription.New: %q cannot be warmed up", person)
+		}
+
+		if title := normalizeTitle(person); !warmingUp[title] {
+			warmingUp[title] = true
+			warmUp = append(warmUp, title)
+		}
+	}
+
+	if cfg.ProbeInterval < 0 {
+		return Describer{}, errors.New("shortdescription.New: ProbeInterval cannot be negative")
+	}
+
+	if cfg.ProbeInterval == 0 {
+		cfg.ProbeInterval = DefaultProbeInterval
+	}
+
+	if cfg.ProbePerson != "" {
+		cfg.ProbePerson = normalizeTitle(cfg.ProbePerson)
+	}
+
+	if cfg.SuggestCacheSize == 0 {
+		cfg.SuggestCacheSize = DefaultSuggestCacheSize
+	}
+
+	if cfg.SuggestTTL == 0 {
+		cfg.SuggestTTL = DefaultSuggestTTL
+	}
+
+	if cfg.SuggestTimeout == 0 {
+		cfg.SuggestTimeout = DefaultSuggestTimeout
+	}
+
+	if cfg.SuggestLimit == 0 {
+		cfg.SuggestLimit = DefaultSuggestLimit
+	}
+
+	if cfg.SuggestLimit < 0 || cfg.SuggestLimit > MaxSuggestLimit {
+		return Describer{}, fmt.Errorf("shortdescription.New: SuggestLimit must be between 1 and %d", MaxSuggestLimit)
+	}
+
+	cache, err := newCache[description](CacheDescriptions, cfg.CacheSize, cfg.CachedTTL, cfg.Metrics)
 	if err != nil {
 		return Describer{}, fmt.Errorf("cache creation failed: %w", err)
 	}
 
-	return Describer{
-		userAgent:  fmt.Sprintf(userAgentFmt, cfg.ContactInfo),
-		httpClient: cfg.HttpClient,
-		cache:      cache,
-	}, nil
+	fallbackCache, err := newCache[string](CacheFallback, cfg.CacheSize, cfg.CachedTTL, cfg.Metrics)
+	if err != nil {
+		return Describer{}, fmt.Errorf("fallback cache creation failed: %w", err)
+	}
+
+	// details are cached per field
+	detailsCache, err := newCache[ShortDescription](CacheDetails, cfg.CacheSize*len(Fields), cfg.DetailsTTL, cfg.Metrics)
+	if err != nil {
+		return Describer{}, fmt.Errorf("details cache creation failed: %w", err)
+	}
+
+	suggestCache, err := newCache[[]string](CacheSuggest, cfg.SuggestCacheSize, cfg.SuggestTTL, cfg.Metrics)
+	if err != nil {
+		return Describer{}, fmt.Errorf("suggest cache creation failed: %w", err)
+	}
+
+	d := Describer{
+		userAgent:       fmt.Sprintf(userAgentFmt, cfg.ContactInfo),
+		httpClient:      cfg.HttpClient,
+		metrics:         cfg.Metrics,
+		accessLogger:    cfg.AccessLogger,
+		limiter:         newUpstreamLimiter(cfg),
+		maxLag:          strconv.Itoa(cfg.MaxLag),
+		clientLimiter:   limiter,
+		clients:         cfg.Clients,
+		requireAPIKey:   cfg.RequireAPIKey,
+		cors:            cors,
+		problemJSON:     cfg.ProblemJSON,
+		bulkConcurrency: cfg.BulkConcurrency,
+		bulkMaxPersons:  cfg.BulkMaxPersons,
+		subscriptions:   newSubscriptions(cfg),
+		health:          newHealth(cfg, warmUp),
+		graphQLLimits: graphQLLimits{
+			maxDepth:      cfg.GraphQLMaxDepth,
+			maxComplexity: cfg.GraphQLMaxComplexity,
+		},
+		cache:          cache,
+		fallbackCache:  fallbackCache,
+		detailsCache:   detailsCache,
+		suggestCache:   suggestCache,
+		suggestTimeout: cfg.SuggestTimeout,
+		suggestLimit:   cfg.SuggestLimit,
+	}
+
+	d.upstreamInFlight = newGauge(cfg.Metrics.SetUpstreamInFlight)
+	d.lookups = newFlightGroup(cfg.Metrics)
+	d.tracer = cfg.TracerProvider.Tracer(tracerName)
+	d.propagator = cfg.Propagator
+
+	d.backend, err = newBackend(cfg, d.fetch)
+	if err != nil {
+		return Describer{}, fmt.Errorf("shortdescription.New: %w", err)
+	}
+
+	d.subscriptions.lookup = d.refreshTitles
+
+	schema, err := newGraphQLSchema(d)
+	if err != nil {
+		return Describer{}, fmt.Errorf("GraphQL schema creation failed: %w", err)
+	}
+
+	d.graphQL = &schema
+
+	if len(warmUp) > 0 {
+		go d.warmUp(warmUp)
+	}
+
+	return d, nil
 }
 
 type Describer struct {
 	userAgent  string
 	httpClient HttpDoer
-	cache      cache
+	metrics    Metrics
+	limiter    *upstreamLimiter
+	maxLag     string
+	backend    backend
+	cache      cache[description]
+
+	upstreamInFlight *gauge       // requests waiting for an answer
+	lookups          *flightGroup // of the descriptions missing from the cache
+
+	accessLogger AccessLogger // nil when requests are not logged
+
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+
+	fallbackCache cache[string]
+	detailsCache  cache[ShortDescription]
+
+	suggestCache   cache[[]string]
+	suggestTimeout time.Duration
+	suggestLimit   int
+
+	clientLimiter *clientLimiter // nil when clients are not limited
+	clients       ClientRegistry // nil when API keys are not validated
+	requireAPIKey bool
+	cors          corsPolicy
+	problemJSON   bool
+
+	bulkConcurrency int
+	bulkMaxPersons  int
+	subscriptions   *subscriptions
+
+	graphQL       *graphql.Schema
+	graphQLLimits graphQLLimits
+
+	health *health
+}
+
+func (d Describer) ShortDescription(ctx context.Context, person, userAgent string, opts ...Option) (ShortDescription, error) {
+	descr, _, err := d.lookup(ctx, person, userAgent, opts...)
+	return descr, err
+}
+
+// lookup is ShortDescription, also returning how fresh the short description is.
+func (d Describer) lookup(ctx context.Context, person, userAgent string, opts ...Option) (ShortDescription, freshness, error) {
+	ctx, span := d.tracer.Start(ctx, "ShortDescription")
+
+	descr, fresh, err := d.describe(ctx, person, userAgent, opts...)
+	endSpan(span, err)
+
+	return descr, fresh, err
 }
 
-func (d Describer) ShortDescription(ctx context.Context, person, userAgent string) (ShortDescription, error) {
+// describe is lookup, within its span.
+func (d Describer) describe(ctx context.Context, person, userAgent string, opts ...Option) (ShortDescription, freshness, error) {
 	if person == "" {
-		return ShortDescription{}, fmt.Errorf("%w: person is empty", ErrInvalidArgument)
+		return ShortDescription{}, freshness{}, fmt.Errorf("%w: person is empty", ErrInvalidArgument)
 	}
 
 	if userAgent == "" {
-		return ShortDescription{}, fmt.Errorf("%w: userAgent is empty", ErrInvalidArgument)
+		return ShortDescription{}, freshness{}, fmt.Errorf("%w: userAgent is empty", ErrInvalidArgument)
+	}
+
+	o := newOptions(opts)
+
+	for _, field := range o.fields {
+		if !field.valid() {
+			return ShortDescription{}, freshness{}, fmt.Errorf("%w: unknown field %q", ErrInvalidArgument, field)
+		}
 	}
 
 	person, err := url.QueryUnescape(person)
 	if err != nil {
-		return ShortDescription{}, fmt.Errorf("%w: person is wrongly encoded: %v", ErrInvalidArgument, err)
+		return ShortDescription{}, freshness{}, fmt.Errorf("%w: person is wrongly encoded: %v", ErrInvalidArgument, err)
 	}
 
-	// Normalize person title first so that caching is more effective. According to
-	// https://www.mediawiki.org/wiki/API:Query this means capitalizing the first character and
-	// replacing underscores with spaces.
 	person = strings.Split(person, "|")[0] // deal with only one query
-	person = strings.ToUpper(person[:1]) + person[1:]
-	person = strings.ReplaceAll(person, "_", " ")
+	if person == "" {
+		return ShortDescription{}, freshness{}, fmt.Errorf("%w: person is empty", ErrInvalidArgument)
+	}
+
+	person = normalizeTitle(person)
+
+	trace.SpanFromContext(ctx).SetAttributes(attrTitle.String(person))
+
+	descr, fetched, outcome := d.cachedDescription(ctx, person)
+	if outcome != CacheHit {
+		// the infobox is the only thing the fields change about the lookup
+		key := person
+		if needsInfobox(o.fields) {
+			key += "|" + string(FieldInfobox)
+		}
+
+		descr, fetched, err = d.lookups.do(ctx, key, func() (description, time.Time, error) {
+			descr, err := d.backend.describe(ctx, person, userAgent, o.fields)
+			if err != nil {
+				return description{}, time.Time{}, err
+			}
+
+			return descr, d.store(person, descr), nil
+		})
+		if err != nil {
+			return ShortDescription{}, freshness{}, err
+		}
+	}
+
+	fresh := freshness{fetched: fetched, expires: d.cache.expiry(fetched), revision: descr.revision}
+	shortDescription := descr.shortDescription(person)
+
+	if descr.none && o.wikidataFallback {
+		shortDescription, err = d.wikidataDescription(ctx, person, userAgent, &fresh)
+		if err != nil {
+			return ShortDescription{}, freshness{}, err
+		}
+	}
+
+	if len(o.fields) > 0 {
+		if err := d.addDetails(ctx, &shortDescription, descr, o.fields, userAgent, &fresh); err != nil {
+			return ShortDescription{}, freshness{}, err
+		}
+	}
+
+	return shortDescription, fresh, nil
+}
+
+// cachedDescription looks title up in the cache.
+func (d Describer) cachedDescription(ctx context.Context, title string) (description, time.Time, CacheOutcome) {
+	_, span := d.tracer.Start(ctx, "cache.lookup", trace.WithAttributes(
+		attrCache.String(CacheDescriptions),
+		attrTitle.String(title),
+	))
+	defer span.End()
+
+	descr, fetched, outcome := d.cache.lookup(title)
+	span.SetAttributes(attrCacheOutcome.String(string(outcome)))
+
+	recordRequest(ctx, func(stats *requestStats) {
+		stats.person, stats.cache = title, outcome
+	})
+
+	return descr, fetched, outcome
+}
+
+// Forget removes everything cached about person, so that it's looked up again. Nothing is
+// cached about empty persons, so they are ignored.
+func (d Describer) Forget(person string) {
+	if strings.TrimSpace(person) == "" {
+		return
+	}
+
+	person = normalizeTitle(person)
 
-	shortDescription, ok := d.cache.Get(person)
-	if ok {
-		return ShortDescription{
-			Person:      person,
-			Description: shortDescription,
-		}, nil
+	d.cache.Remove(person)
+	d.fallbackCache.Remove(person)
+	d.subscriptions.invalidate(person)
+
+	for _, field := range Fields {
+		d.detailsCache.Remove(detailsKey(person, field))
 	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", getShortDescriptionURL(person), nil)
+// fetch sends a GET request to the Wikimedia APIs and checks the response for errors.
+// The caller is responsible for closing the response body.
+//
+// Requests are paced by the upstream limiter, and sent again if the API asks to back off.
+func (d Describer) fetch(ctx context.Context, url, userAgent string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return ShortDescription{}, fmt.Errorf("cannot create request: %w", err)
+		return nil, fmt.Errorf("cannot create request: %w", err)
 	}
 
 	// required by the API
 	req.Header.Set("User-Agent", userAgent)
-	req.Header.Set("Api-User-Agent", d.userAgent)
+	req.Header.Set("Api-User-Agent", d.apiUserAgent(ctx))
+
+	if id, ok := RequestIDFromContext(ctx); ok {
+		req.Header.Set(requestIDHeader, id)
+	}
 
 	// the "Accept-Encoding" is automatically set so there's no need to add "gzip".
 
-	res, err := d.httpClient.Do(req)
-	if err != nil {
-		return ShortDescription{}, fmt.Errorf("failed to initiate fetch: %w", err)
+	api := "rest"
+
+	// be polite and let the Action API refuse requests while its databases are lagged
+	if strings.HasSuffix(req.URL.Path, "/api.php") {
+		api = "action"
+
+		query := req.URL.Query()
+		query.Set("maxlag", d.maxLag)
+		req.URL.RawQuery = query.Encode()
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := d.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		recordRequest(ctx, func(stats *requestStats) { stats.attempts++ })
+
+		start := time.Now()
+
+		res, err := d.do(req.Clone(ctx), api, attempt)
+		if err != nil {
+			d.metrics.ObserveUpstreamRequest(api, "network", time.Since(start))
+			return nil, fetchError(err)
+		}
+
+		if backoff, ok := backoffSignal(res); ok {
+			d.metrics.ObserveUpstreamRequest(api, "backoff", time.Since(start))
+			res.Body.Close()
+			d.limiter.pause(backoff)
+
+			if attempt < maxBackoffAttempts {
+				continue
+			}
+
+			return nil, fmt.Errorf("%w: upstream keeps asking to back off", ErrUnavailable)
+		}
+
+		if err := responseError(res); err != nil {
+			_, code := errorCode(err)
+			d.metrics.ObserveUpstreamRequest(api, code, time.Since(start))
+			res.Body.Close()
+			return nil, err
+		}
+
+		d.metrics.ObserveUpstreamRequest(api, "ok", time.Since(start))
+
+		return res, nil
 	}
+}
 
-	defer res.Body.Close()
+// fetchError tells why a request to the Wikimedia APIs got no response: it timed out, which
+// is ErrUnavailable, or it failed, which is ErrUpstream, unless it was cancelled.
+func fetchError(err error) error {
+	var netErr net.Error
 
-	if err := responseError(res); err != nil {
-		return ShortDescription{}, err
+	switch {
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("failed to initiate fetch: %w", err)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return timeoutError{err}
+	default:
+		return fmt.Errorf("%w: failed to initiate fetch: %v", ErrUpstream, err)
 	}
+}
+
+// timeoutError is ErrUnavailable, while still being the timeout error it wraps.
+type timeoutError struct {
+	err error
+}
+
+func (e timeoutError) Error() string {
+	return fmt.Sprintf("%v: the request timed out: %v", ErrUnavailable, e.err)
+}
+
+func (e timeoutError) Is(target error) bool {
+	return target == ErrUnavailable
+}
+
+func (e timeoutError) Unwrap() error {
+	return e.err
+}
+
+// do sends a request to the Wikimedia APIs, keeping track of those in flight. Its span
+// lasts until the response is closed.
+func (d Describer) do(req *http.Request, api string, attempt int) (*http.Response, error) {
+	d.upstreamInFlight.add(1)
+	defer d.upstreamInFlight.add(-1)
 
-	shortDescription, err = readShortDescription(res.Body)
+	req, span := d.traceUpstream(req, api, attempt)
+
+	res, err := d.httpClient.Do(req)
 	if err != nil {
-		return ShortDescription{}, err
+		endSpan(span, fetchError(err))
+		return nil, err
 	}
 
-	d.cache.Add(person, shortDescription)
+	span.SetAttributes(semconv.HTTPStatusCode(res.StatusCode))
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, res.Status)
+	}
+
+	res.Body = &tracedBody{ReadCloser: res.Body, span: span}
+
+	return res, nil
+}
+
+// UpstreamQueueDepth returns the amount of requests waiting for the upstream rate limiter.
+func (d Describer) UpstreamQueueDepth() int {
+	return d.limiter.queueDepth()
+}
 
-	return ShortDescription{
-		Person:      person,
-		Description: shortDescription,
-	}, nil
+// normalizeTitle makes caching more effective. According to
+// https://www.mediawiki.org/wiki/API:Query this means capitalizing the first character and
+// replacing underscores with spaces.
+func normalizeTitle(title string) string {
+	title = strings.ToUpper(title[:1]) + title[1:]
+	return strings.ReplaceAll(title, "_", " ")
 }
@@ -1,53 +1,552 @@
 package shortdescription
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Handler serves a Describer over HTTP:
+//
+//	GET    /v1/descriptions/{person}
+//	GET    /v1/descriptions?person=
+//	GET    /v1/batch?person=&person=
+//	POST   /v1/bulk
+//	GET    /v1/subscribe?person=&person=
+//	GET    /v1/suggest?prefix=
+//	GET    /v1/health
+//	GET    /healthz
+//	GET    /readyz
+//	POST   /graphql
+//	POST   /v1/admin/reload
+//	DELETE /v1/admin/cache?person=
+//	GET    /openapi.json
+//	GET    /docs
+//
+// as described by OpenAPI. "/" and "/suggest" are kept as aliases of the first versions of the
+// API. Paths are relative to where the Handler is mounted, so wrap it in http.StripPrefix to
+// serve it under a prefix.
+type Handler struct {
+	d Describer
+}
+
+func NewHandler(d Describer) Handler {
+	return Handler{d: d}
+}
+
+// ServeHTTP serves the Describer at the root path.
+//
+// Deprecated: mount a Handler, see NewHandler.
 func (d Describer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	// allow multiple origins / client websites
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+	NewHandler(d).ServeHTTP(w, req)
+}
+
+// ServeHTTP tags each request with the X-Request-ID sent by the client, or a new one, which
+// is answered back and sent along to the Wikimedia APIs. So is the trace context the client
+// sent in the traceparent header.
+func (h Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+
+	id := requestID(req)
+	w.Header().Set(requestIDHeader, id)
+
+	routed := route(req.URL.EscapedPath())
+
+	ctx := h.d.propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+	ctx, span := h.d.tracer.Start(ctx, req.Method+" "+routed,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPMethod(req.Method),
+			semconv.HTTPRoute(routed),
+			semconv.HTTPTarget(req.URL.EscapedPath()), // the query may carry an API key
+			semconv.UserAgentOriginal(req.UserAgent()),
+			attrRequestID.String(id),
+		),
+	)
+	defer span.End()
+
+	stats := &requestStats{}
+	req = req.WithContext(contextWithRequestStats(ContextWithRequestID(ctx, id), stats))
+
+	sw := &statusWriter{ResponseWriter: w}
+
+	h.serve(sw, req)
+
+	latency := time.Since(start)
+
+	span.SetAttributes(semconv.HTTPStatusCode(sw.statusCode()))
+
+	if sw.statusCode() >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(sw.statusCode()))
+	}
+
+	h.d.metrics.ObserveRequest(routed, sw.statusCode(), latency)
+
+	if h.d.accessLogger == nil {
+		return
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	entry := AccessLogEntry{
+		RequestID:        id,
+		Method:           req.Method,
+		Path:             req.URL.Path,
+		Person:           stats.person,
+		Status:           sw.statusCode(),
+		Latency:          latency,
+		Cache:            stats.cache,
+		UpstreamAttempts: stats.attempts,
+		UserAgent:        req.UserAgent(),
+	}
 
-	if req.Method != http.MethodGet {
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	if stats.err != nil {
+		entry.Error = stats.err.Error()
+	}
+
+	h.d.accessLogger.LogRequest(entry)
+}
+
+func (h Handler) serve(w http.ResponseWriter, req *http.Request) {
+	d := h.d
+
+	// preflight requests carry no credentials, so they are answered first
+	if d.cors.handle(w, req) {
+		return
+	}
+
+	// the documentation and the probes are public
+	switch req.URL.Path {
+	case "/healthz", "/v1/health":
+		if h.allowMethod(w, req, http.MethodGet) {
+			writeJSON(w, map[string]string{"status": StatusOK})
+		}
+
+		return
+	case "/readyz":
+		if h.allowMethod(w, req, http.MethodGet) {
+			h.serveReadiness(w, req)
+		}
+
+		return
+	case "/openapi.json":
+		if h.allowMethod(w, req, http.MethodGet) {
+			serveOpenAPI(w, req)
+		}
+
+		return
+	case "/docs":
+		if h.allowMethod(w, req, http.MethodGet) {
+			serveDocs(w, req)
+		}
+
+		return
+	}
+
+	ctx, err := d.Admit(req, w.Header())
+	if err != nil {
+		d.writeError(w, req, err)
 		return
 	}
 
+	req = req.WithContext(ctx)
+
+	path := req.URL.EscapedPath()
+
+	switch {
+	case path == "/", path == "/v1/descriptions":
+		if h.allowMethod(w, req, http.MethodGet) {
+			h.serveDescription(w, req, req.URL.Query().Get("person"), false)
+		}
+	case strings.HasPrefix(path, "/v1/descriptions/"):
+		// titles can have slashes, such as "AC/DC", so the rest of the path is the person
+		person, err := url.PathUnescape(strings.TrimPrefix(path, "/v1/descriptions/"))
+		if err != nil {
+			d.writePersonError(w, req, strings.TrimPrefix(path, "/v1/descriptions/"),
+				fmt.Errorf("%w: the person is wrongly encoded: %v", ErrInvalidArgument, err))
+			return
+		}
+
+		if h.allowPersonMethod(w, req, person, http.MethodGet) {
+			h.serveDescription(w, req, person, true)
+		}
+	case path == "/v1/batch":
+		if h.allowMethod(w, req, http.MethodGet) {
+			h.serveBatch(w, req)
+		}
+	case path == "/v1/bulk":
+		if h.allowMethod(w, req, http.MethodPost) {
+			h.serveBulk(w, req)
+		}
+	case path == "/v1/subscribe":
+		if h.allowMethod(w, req, http.MethodGet) {
+			h.serveSubscribe(w, req)
+		}
+	case path == "/suggest", path == "/v1/suggest":
+		if h.allowMethod(w, req, http.MethodGet) {
+			h.serveSuggest(w, req)
+		}
+	case path == "/graphql":
+		h.serveGraphQL(w, req)
+	case path == "/v1/admin/reload":
+		if h.allowMethod(w, req, http.MethodPost) && h.allowAdmin(w, req) {
+			h.serveReload(w, req)
+		}
+	case path == "/v1/admin/cache":
+		if h.allowMethod(w, req, http.MethodDelete) && h.allowAdmin(w, req) {
+			h.serveForget(w, req)
+		}
+	default:
+		d.writeError(w, req, fmt.Errorf("%w: %s", errUnknownPath, path))
+	}
+}
+
+// routes are the paths a Handler serves, as reported to Metrics.
+var routes = map[string]bool{
+	"/": true, "/v1/descriptions": true, "/v1/batch": true, "/v1/bulk": true, "/v1/subscribe": true,
+	"/suggest": true, "/v1/suggest": true, "/graphql": true, "/v1/health": true, "/healthz": true,
+	"/readyz": true, "/v1/admin/reload": true, "/v1/admin/cache": true, "/openapi.json": true, "/docs": true,
+}
+
+// route returns the route of a path, keeping persons and unknown paths out of the metrics.
+func route(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/v1/descriptions/"):
+		return "/v1/descriptions/{person}"
+	case routes[path]:
+		return path
+	default:
+		return "other"
+	}
+}
+
+// statusWriter remembers the status code of a response, for the metrics.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+
+	return w.status
+}
+
+// Flush keeps streaming working through the statusWriter.
+
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (h Handler) allowMethod(w http.ResponseWriter, req *http.Request, method string) bool {
+	return h.allowPersonMethod(w, req, req.URL.Query().Get("person"), method)
+}
+
+// allowPersonMethod is allowMethod for the requests about person, as sent by the client.
+func (h Handler) allowPersonMethod(w http.ResponseWriter, req *http.Request, person, method string) bool {
+	if req.Method == method {
+		return true
+	}
+
+	w.Header().Set("Allow", method)
+	h.d.writePersonError(w, req, person, fmt.Errorf("%w: only %s requests are accepted", errMethodNotAllowed, method))
+
+	return false
+}
+
+func (h Handler) allowAdmin(w http.ResponseWriter, req *http.Request) bool {
+	if client, ok := clientFromContext(req.Context()); ok && client.Admin {
+		return true
+	}
+
+	h.d.writeError(w, req, fmt.Errorf("%w: an admin API key is required", ErrUnauthorized))
+
+	return false
+}
+
+// serveDescription looks person up, as sent by the client in the query or in the path.
+func (h Handler) serveDescription(w http.ResponseWriter, req *http.Request, person string, inPath bool) {
+	d := h.d
 	query := req.URL.Query()
 
-	person := query.Get("person")
 	if person == "" {
-		http.Error(w, "the 'person' query parameter cannot be empty", http.StatusBadRequest)
+		d.writePersonError(w, req, person, fmt.Errorf("%w: the 'person' query parameter cannot be empty", ErrInvalidArgument))
 		return
 	}
 
-	descr, err := d.ShortDescription(req.Context(), person, req.UserAgent())
+	f, err := negotiateFormat(req)
 	if err != nil {
-		errCode := http.StatusInternalServerError
-		if errors.Is(err, ErrUpstream) {
-			errCode = http.StatusBadGateway
+		d.writePersonError(w, req, person, err)
+		return
+	}
+
+	var opts []Option
+
+	switch query.Get("fallback") {
+	case "":
+	case SourceWikidata:
+		opts = append(opts, WithWikidataFallback())
+	default:
+		d.writePersonError(w, req, person, fmt.Errorf("%w: the 'fallback' query parameter only accepts %q", ErrInvalidArgument, SourceWikidata))
+		return
+	}
+
+	if fields := query.Get("fields"); fields != "" {
+		var requested []Field
+		for _, field := range strings.Split(fields, ",") {
+			requested = append(requested, Field(strings.TrimSpace(field)))
 		}
 
-		if errors.Is(err, ErrNotFound) {
-			errCode = http.StatusNotFound
+		opts = append(opts, WithFields(requested...))
+	}
+
+	title := person
+	if inPath {
+		// ShortDescription unescapes the person, as it comes in query parameters
+		title = url.QueryEscape(person)
+	}
+
+	descr, fresh, err := d.lookup(req.Context(), title, req.UserAgent(), opts...)
+	if err != nil {
+		d.writePersonError(w, req, person, err)
+		return
+	}
+
+	d.writeCacheable(w, req, descr, f, fresh)
+}
+
+func (h Handler) serveBatch(w http.ResponseWriter, req *http.Request) {
+	batch, err := h.d.ShortDescriptions(req.Context(), req.URL.Query()["person"], req.UserAgent())
+	if err != nil {
+		h.d.writeError(w, req, err)
+		return
+	}
+
+	writeJSON(w, batch)
+}
+
+// serveBulk streams the results of a bulk request as newline-delimited JSON, as they
+// complete. See Describer.Bulk.
+//
+// The request is read in full first, so that it can be refused with an error response when
+// it's over the limits, rather than with an error line once the status is sent.
+func (h Handler) serveBulk(w http.ResponseWriter, req *http.Request) {
+	if req.UserAgent() == "" {
+		h.d.writeError(w, req, fmt.Errorf("%w: userAgent is empty", ErrInvalidArgument))
+		return
+	}
+
+	body, err := bufferBulk(req.Body, h.d.bulkMaxPersons)
+	if err != nil {
+		h.d.writeError(w, req, err)
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+
+	err = h.d.Bulk(req.Context(), bytes.NewReader(body), req.UserAgent(), func(result BulkResult) error {
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		return nil
+	})
+	if err != nil {
+		// the status is already sent, such as when the client went away, so it's only logged
+		recordRequest(req.Context(), func(stats *requestStats) { stats.err = err })
+	}
+}
+
+// keepAliveInterval is how often comments are sent on idle event streams, so that proxies
+// don't close them.
+const keepAliveInterval = 30 * time.Second
+
+// serveReadiness answers the readiness checks, with a 503 Service Unavailable when the
+// Describer is not ready. See Describer.Readiness.
+func (h Handler) serveReadiness(w http.ResponseWriter, req *http.Request) {
+	readiness := h.d.Readiness(req.Context())
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+
+	if readiness.Status == StatusUnavailable {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	writeJSON(w, readiness)
+}
+
+// serveSubscribe streams the updates of the short descriptions of the persons as
+// server-sent events. See Describer.Subscribe.
+func (h Handler) serveSubscribe(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.d.writeError(w, req, fmt.Errorf("%w: the response cannot be streamed", ErrInternal))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	updates, err := h.d.Subscribe(ctx, req.URL.Query()["person"], req.UserAgent())
+	if err != nil {
+		h.d.writeError(w, req, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // for nginx
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return // the client fell behind, and can reconnect
+			}
+
+			data, err := json.Marshal(update)
+			if err != nil {
+				return
+			}
+
+			if _, err := fmt.Fprintf(w, "event: description\ndata: %s\n\n", data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
 		}
 
-		if errors.Is(err, ErrInvalidArgument) {
-			errCode = http.StatusBadRequest
+		flusher.Flush()
+	}
+}
+
+func (h Handler) serveSuggest(w http.ResponseWriter, req *http.Request) {
+	d := h.d
+	query := req.URL.Query()
+
+	prefix := query.Get("prefix")
+	if prefix == "" {
+		d.writeError(w, req, fmt.Errorf("%w: the 'prefix' query parameter cannot be empty", ErrInvalidArgument))
+		return
+	}
+
+	var limit int
+
+	if l := query.Get("limit"); l != "" {
+		var err error
+
+		limit, err = strconv.Atoi(l)
+		if err != nil {
+			d.writeError(w, req, fmt.Errorf("%w: the 'limit' query parameter must be a number", ErrInvalidArgument))
+			return
 		}
+	}
+
+	suggestions, err := d.Suggest(req.Context(), prefix, req.UserAgent(), limit)
+	if err != nil {
+		d.writeError(w, req, err)
+		return
+	}
 
-		http.Error(w, err.Error(), errCode)
+	writeJSON(w, suggestions)
+}
+
+// reloader is implemented by the client registries that can be reloaded, such as
+// FileClientRegistry.
+type reloader interface {
+	Reload() error
+}
 
+func (h Handler) serveReload(w http.ResponseWriter, req *http.Request) {
+	registry, ok := h.d.clients.(reloader)
+	if !ok {
+		h.d.writeError(w, req, fmt.Errorf("%w: the client registry cannot be reloaded", ErrInvalidArgument))
 		return
 	}
 
+	if err := registry.Reload(); err != nil {
+		h.d.writeError(w, req, fmt.Errorf("%w: %v", ErrInternal, err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h Handler) serveForget(w http.ResponseWriter, req *http.Request) {
+	person := req.URL.Query().Get("person")
+	if strings.TrimSpace(person) == "" {
+		h.d.writeError(w, req, fmt.Errorf("%w: the 'person' query parameter cannot be empty", ErrInvalidArgument))
+		return
+	}
+
+	h.d.Forget(person)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
 
-	if err := json.NewEncoder(w).Encode(descr); err != nil {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
 		http.Error(w,
-			"error while encoding the short description: "+err.Error(),
+			"error while encoding the response: "+err.Error(),
 			http.StatusInternalServerError,
 		)
 	}
@@ -2,7 +2,11 @@ package shortdescription_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 
 	shortdescription "github.com/Inuart/wikimedia-exercise"
@@ -24,6 +28,14 @@ func TestNewDescriptor(t *testing.T) {
 			name:      "fail at creating a new descriptor without an API key",
 			expectErr: true,
 		},
+		{
+			name: "fail at creating a new descriptor with an unknown backend",
+			cfg: shortdescription.Config{
+				ContactInfo: "testKey",
+				Backend:     "unknown",
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -137,3 +149,459 @@ func TestDescriptorClient(t *testing.T) {
 		})
 	}
 }
+
+func TestNoneDescription(t *testing.T) {
+	ctx := context.Background()
+
+	const (
+		nonePerson          = "Jane Doe"
+		noneNorWikidata     = "John Doe"
+		emptyPerson         = "Richard Roe"
+		wikidataDescription = "Placeholder name"
+	)
+
+	upstream := handlerClient(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+
+		if query.Get("prop") == "description" {
+			descr := ""
+			if query.Get("titles") == nonePerson {
+				descr = wikidataDescription
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"query": map[string]any{"pages": []any{
+				map[string]any{"title": query.Get("titles"), "description": descr},
+			}}})
+
+			return
+		}
+
+		if query.Get("titles") == emptyPerson {
+			_, _ = w.Write([]byte("{{Short description|}}"))
+			return
+		}
+
+		_, _ = w.Write([]byte("{{Short description|None}}"))
+	})
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  upstream,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		person   string
+		opts     []shortdescription.Option
+		expected shortdescription.ShortDescription
+	}{
+		{
+			name:     "none is reported as its own state",
+			person:   nonePerson,
+			expected: shortdescription.ShortDescription{Person: nonePerson, None: true},
+		},
+		{
+			name:     "an explicitly empty description is none",
+			person:   emptyPerson,
+			expected: shortdescription.ShortDescription{Person: emptyPerson, None: true},
+		},
+		{
+			name:   "callers can opt into the wikidata fallback",
+			person: nonePerson,
+			opts:   []shortdescription.Option{shortdescription.WithWikidataFallback()},
+			expected: shortdescription.ShortDescription{
+				Person:      nonePerson,
+				Description: wikidataDescription,
+				None:        true,
+				Source:      shortdescription.SourceWikidata,
+			},
+		},
+		{
+			name:     "none is kept if wikidata has no description either",
+			person:   noneNorWikidata,
+			opts:     []shortdescription.Option{shortdescription.WithWikidataFallback()},
+			expected: shortdescription.ShortDescription{Person: noneNorWikidata, None: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			descr, err := descriptor.ShortDescription(ctx, tc.person, testUserAgent, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if descr != tc.expected {
+				t.Errorf("wanted %+v, got %+v", tc.expected, descr)
+			}
+		})
+	}
+
+	client := startTestServer(t, descriptor)
+
+	res, err := client.Get(client.url + "?fallback=unknown&person=" + url.QueryEscape(nonePerson))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("wanted %v for an unknown fallback, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestSummaryBackend(t *testing.T) {
+	ctx := context.Background()
+
+	const centralPerson = "Jane Doe"
+
+	upstream := handlerClient(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("list") == "prefixsearch" {
+			_, _ = w.Write([]byte(`{"query":{"prefixsearch":[{"title":"Yoshua Bengio"},{"title":"Jane Doe"},{"title":"Yoshua Lo"}]}}`))
+			return
+		}
+
+		switch req.URL.EscapedPath() {
+		case "/api/rest_v1/page/summary/Yoshua_Bengio":
+			_, _ = w.Write([]byte(`{"title":"Yoshua Bengio","description":"` + testDescription + `","description_source":"local"}`))
+		case "/api/rest_v1/page/summary/Jane_Doe":
+			_, _ = w.Write([]byte(`{"title":"Jane Doe","description":"Placeholder name","description_source":"central"}`))
+		case "/api/rest_v1/page/summary/Yoshua_Lo":
+			_, _ = w.Write([]byte(`{"title":"Yoshua Lo"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  upstream,
+		Backend:     shortdescription.BackendSummary,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name        string
+		person      string
+		expected    shortdescription.ShortDescription
+		expectedErr error
+	}{
+		{
+			name:     "happy path returns a short description",
+			person:   testNonCanonicalPerson,
+			expected: shortdescription.ShortDescription{Person: testPerson, Description: testDescription},
+		},
+		{
+			name:   "wikidata descriptions are marked as such",
+			person: centralPerson,
+			expected: shortdescription.ShortDescription{
+				Person:      centralPerson,
+				Description: "Placeholder name",
+				Source:      shortdescription.SourceWikidata,
+			},
+		},
+		{
+			name:     "pages without a description have none",
+			person:   "Yoshua Lo",
+			expected: shortdescription.ShortDescription{Person: "Yoshua Lo", None: true},
+		},
+		{
+			name:        "fails if the page does not exist",
+			person:      "unknown person",
+			expectedErr: shortdescription.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			descr, err := descriptor.ShortDescription(ctx, tc.person, testUserAgent)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("wanted %v, got %v", tc.expectedErr, err)
+			}
+
+			if descr != tc.expected {
+				t.Errorf("wanted %+v, got %+v", tc.expected, descr)
+			}
+		})
+	}
+
+	t.Run("batches are fetched concurrently", func(t *testing.T) {
+		suggestions, err := descriptor.Suggest(ctx, testPrefix, testUserAgent, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkSuggestions(t, suggestions.Suggestions, map[string]string{
+			testPerson:    testDescription,
+			centralPerson: "Placeholder name",
+			"Yoshua Lo":   "",
+		})
+	})
+}
+
+func TestDescriptionBackend(t *testing.T) {
+	ctx := context.Background()
+
+	const nonePerson = "Jane Doe"
+
+	var wikitextTitles []string
+
+	upstream := handlerClient(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		titles := strings.Split(query.Get("titles"), "|")
+
+		var pages []any
+
+		switch query.Get("prop") {
+		case "description|info":
+			if query.Get("descprefersource") != "local" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			for _, title := range titles {
+				switch title {
+				case testPerson:
+					pages = append(pages, map[string]any{"title": title, "description": testDescription, "descriptionsource": "local"})
+				case nonePerson:
+					pages = append(pages, map[string]any{"title": title, "description": "Placeholder name", "descriptionsource": "central"})
+				case "Yoshua Lo":
+					pages = append(pages, map[string]any{"title": title})
+				default:
+					pages = append(pages, map[string]any{"title": title, "missing": true})
+				}
+			}
+
+		case "revisions":
+			wikitextTitles = append(wikitextTitles, titles...)
+
+			for _, title := range titles {
+				content := "no description here"
+				if title == nonePerson {
+					content = "{{Short description|none}}"
+				}
+
+				pages = append(pages, map[string]any{
+					"title":     title,
+					"revisions": []any{map[string]any{"slots": map[string]any{"main": map[string]any{"content": content}}}},
+				})
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"query": map[string]any{"pages": pages}})
+	})
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  upstream,
+		Backend:     shortdescription.BackendDescription,
+		CachedTTL:   -1, // remove caching
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name             string
+		person           string
+		expected         shortdescription.ShortDescription
+		expectedErr      error
+		expectedWikitext bool
+	}{
+		{
+			name:     "local descriptions come from the description property",
+			person:   testPerson,
+			expected: shortdescription.ShortDescription{Person: testPerson, Description: testDescription},
+		},
+		{
+			name:             "explicit none descriptions are found in the wikitext",
+			person:           nonePerson,
+			expected:         shortdescription.ShortDescription{Person: nonePerson, None: true},
+			expectedWikitext: true,
+		},
+		{
+			name:             "pages without a description property fall back to the wikitext",
+			person:           "Yoshua Lo",
+			expectedErr:      shortdescription.ErrNotFound,
+			expectedWikitext: true,
+		},
+		{
+			name:        "missing pages are not looked up in the wikitext",
+			person:      "unknown person",
+			expectedErr: shortdescription.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wikitextTitles = nil
+
+			descr, err := descriptor.ShortDescription(ctx, tc.person, testUserAgent)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("wanted %v, got %v", tc.expectedErr, err)
+			}
+
+			if descr != tc.expected {
+				t.Errorf("wanted %+v, got %+v", tc.expected, descr)
+			}
+
+			if usedWikitext := len(wikitextTitles) > 0; usedWikitext != tc.expectedWikitext {
+				t.Errorf("wanted wikitext to be used: %v, got %v", tc.expectedWikitext, usedWikitext)
+			}
+		})
+	}
+}
+
+type bytesReadRecorder struct {
+	shortdescription.NoMetrics
+	observed []int64
+}
+
+func (r *bytesReadRecorder) ObserveBytesRead(n int64) {
+	r.observed = append(r.observed, n)
+}
+
+func TestScanLimit(t *testing.T) {
+	ctx := context.Background()
+
+	content := wikiJSON(strings.Repeat("Some introductory line.\n", 20) + testContent)
+
+	testCases := []struct {
+		name        string
+		limitBytes  int64
+		limitLines  int
+		expectedErr error
+		maxRead     int64
+	}{
+		{
+			name:    "no limits",
+			maxRead: int64(len(content)),
+		},
+		{
+			name:        "stops after the line limit",
+			limitLines:  10,
+			expectedErr: shortdescription.ErrNotFound,
+			maxRead:     int64(len(content)),
+		},
+		{
+			name:       "finds the description within the line limit",
+			limitLines: 21,
+			maxRead:    int64(len(content)),
+		},
+		{
+			name:        "stops after the byte limit",
+			limitBytes:  100,
+			expectedErr: shortdescription.ErrNotFound,
+			maxRead:     100,
+		},
+		{
+			name:       "finds the description within the byte limit",
+			limitBytes: int64(len(content)),
+			maxRead:    int64(len(content)),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var metrics bytesReadRecorder
+
+			descriptor, err := shortdescription.New(shortdescription.Config{
+				ContactInfo:    testContactInfo,
+				HttpClient:     mockHttpClient{body: content},
+				Metrics:        &metrics,
+				ScanLimitBytes: tc.limitBytes,
+				ScanLimitLines: tc.limitLines,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			descr, err := descriptor.ShortDescription(ctx, testPerson, testUserAgent)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("wanted %v, got %v", tc.expectedErr, err)
+			}
+
+			if err == nil && descr.Description != testDescription {
+				t.Errorf("wanted %s, got %s", testDescription, descr.Description)
+			}
+
+			if len(metrics.observed) != 1 || metrics.observed[0] == 0 || metrics.observed[0] > tc.maxRead {
+				t.Errorf("wanted a single observation of at most %d bytes read, got %v", tc.maxRead, metrics.observed)
+			}
+		})
+	}
+}
+
+func TestInfoboxOnlyScannedWhenRequested(t *testing.T) {
+	ctx := context.Background()
+
+	content := wikiJSON("{{Short description|" + testDescription + "}}\n" +
+		strings.Repeat("Some introductory line.\n", 1000) + "{{Infobox person\n| name = Someone\n}}")
+
+	testCases := []struct {
+		name    string
+		opts    []shortdescription.Option
+		minRead int64
+		maxRead int64
+	}{
+		{
+			name:    "plain lookup stops after the description",
+			maxRead: int64(len(content)) / 2,
+		},
+		{
+			name:    "infobox lookup reads up to the infobox",
+			opts:    []shortdescription.Option{shortdescription.WithFields(shortdescription.FieldInfobox)},
+			minRead: int64(len(content)) / 2,
+			maxRead: int64(len(content)),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var metrics bytesReadRecorder
+
+			descriptor, err := shortdescription.New(shortdescription.Config{
+				ContactInfo: testContactInfo,
+				HttpClient:  mockHttpClient{body: content},
+				Metrics:     &metrics,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			descr, err := descriptor.ShortDescription(ctx, testPerson, testUserAgent, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if descr.Description != testDescription {
+				t.Errorf("wanted %s, got %s", testDescription, descr.Description)
+			}
+
+			if len(metrics.observed) != 1 || metrics.observed[0] < tc.minRead || metrics.observed[0] > tc.maxRead {
+				t.Errorf("wanted a single observation of %d to %d bytes read, got %v", tc.minRead, tc.maxRead, metrics.observed)
+			}
+		})
+	}
+}
+
+func TestForgetEmpty(t *testing.T) {
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  mockHttpClient{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is cached about them, and they must not panic
+	for _, person := range []string{"", " ", "\t"} {
+		descriptor.Forget(person)
+	}
+}
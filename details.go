@@ -0,0 +1,216 @@
+package shortdescription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Field is an optional part of a ShortDescription that has to be requested. See WithFields.
+type Field string
+
+const (
+	FieldExtract   Field = "extract"   // first paragraph of the page
+	FieldThumbnail Field = "thumbnail" // URL of the page image
+	FieldDates     Field = "dates"     // birth and death dates from the infobox
+	FieldURL       Field = "url"       // canonical URL of the page
+	FieldInfobox   Field = "infobox"   // facts from the infobox of people
+)
+
+// Fields are all the fields that can be requested.
+var Fields = []Field{FieldExtract, FieldThumbnail, FieldDates, FieldURL, FieldInfobox}
+
+// needsInfobox tells whether any of the fields is taken from the infobox.
+func needsInfobox(fields []Field) bool {
+	for _, field := range fields {
+		if field == FieldInfobox || field == FieldDates {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f Field) valid() bool {
+	for _, field := range Fields {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// thumbnailSize is the width in pixels of the requested thumbnails.
+const thumbnailSize = 320
+
+const detailsURL apiURL = "https://en.wikipedia.org/w/api.php?action=query&formatversion=2&format=json&"
+
+// getDetailsURL asks for the properties the fields need, and only for those.
+func getDetailsURL(title string, fields []Field) string {
+	var props []string
+
+	params := url.Values{"titles": {title}}
+
+	addProp := func(prop string) {
+		for _, p := range props {
+			if p == prop {
+				return
+			}
+		}
+
+		props = append(props, prop)
+	}
+
+	for _, field := range fields {
+		switch field {
+		case FieldExtract:
+			addProp("extracts")
+			params.Set("exintro", "1")
+			params.Set("explaintext", "1")
+		case FieldThumbnail:
+			addProp("pageimages")
+			params.Set("piprop", "thumbnail")
+			params.Set("pithumbsize", strconv.Itoa(thumbnailSize))
+		case FieldDates, FieldInfobox:
+			addProp("revisions")
+			params.Set("rvprop", "content")
+			params.Set("rvslots", "main")
+		case FieldURL:
+			addProp("info")
+			params.Set("inprop", "url")
+		}
+	}
+
+	params.Set("prop", strings.Join(props, "|"))
+
+	return string(detailsURL) + params.Encode()
+}
+
+type detailsResponse struct {
+	Query struct {
+		Pages []struct {
+			wikiPage
+			Extract   string `json:"extract"`
+			Thumbnail struct {
+				Source string `json:"source"`
+			} `json:"thumbnail"`
+			CanonicalURL string `json:"canonicalurl"`
+		} `json:"pages"`
+	} `json:"query"`
+}
+
+// addDetails fills in the requested fields of descr. Each field is cached on its own so
+// that only the ones missing from the cache are fetched. The infobox facts are taken from
+// the page description instead if it was already looked for there. The cache entries the
+// fields come from are taken into account by fresh.
+func (d Describer) addDetails(ctx context.Context, descr *ShortDescription, page description, fields []Field, userAgent string, fresh *freshness) error {
+	var missing []Field
+
+	for _, field := range fields {
+		if page.hasInfobox && (field == FieldInfobox || field == FieldDates) {
+			descr.setField(field, infoboxDetails(page.infobox))
+			continue
+		}
+
+		if cached, added, ok := d.detailsCache.getEntry(detailsKey(descr.Person, field)); ok {
+			descr.setField(field, cached)
+			fresh.cachedUntil(d.detailsCache.expiry(added))
+
+			continue
+		}
+
+		missing = append(missing, field)
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	res, err := d.fetch(ctx, getDetailsURL(descr.Person, missing), userAgent)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	var dr detailsResponse
+	if err := json.NewDecoder(res.Body).Decode(&dr); err != nil {
+		return fmt.Errorf("%w: cannot decode details response: %v", ErrUpstream, err)
+	}
+
+	var fetched ShortDescription
+
+	for _, page := range dr.Query.Pages {
+		fetched.Extract = firstParagraph(page.Extract)
+		fetched.Thumbnail = page.Thumbnail.Source
+		fetched.URL = page.CanonicalURL
+
+		if len(page.Revisions) > 0 {
+			infobox, err := findInfobox(strings.NewReader(page.Revisions[0].Slots.Main.Content))
+			if err != nil {
+				return err
+			}
+
+			withInfobox := infoboxDetails(infobox)
+			fetched.BirthDate = withInfobox.BirthDate
+			fetched.DeathDate = withInfobox.DeathDate
+			fetched.Infobox = withInfobox.Infobox
+		}
+	}
+
+	for _, field := range missing {
+		added := d.detailsCache.Add(detailsKey(descr.Person, field), fetched)
+		fresh.cachedUntil(d.detailsCache.expiry(added))
+		descr.setField(field, fetched)
+	}
+
+	return nil
+}
+
+func detailsKey(person string, field Field) string {
+	return string(field) + "|" + person
+}
+
+// setField copies a single field from another ShortDescription.
+func (sd *ShortDescription) setField(field Field, from ShortDescription) {
+	switch field {
+	case FieldExtract:
+		sd.Extract = from.Extract
+	case FieldThumbnail:
+		sd.Thumbnail = from.Thumbnail
+	case FieldDates:
+		sd.BirthDate = from.BirthDate
+		sd.DeathDate = from.DeathDate
+	case FieldURL:
+		sd.URL = from.URL
+	case FieldInfobox:
+		sd.Infobox = from.Infobox
+	}
+}
+
+// infoboxDetails returns the fields that come from the infobox.
+func infoboxDetails(infobox *Infobox) ShortDescription {
+	if infobox == nil {
+		return ShortDescription{}
+	}
+
+	return ShortDescription{
+		BirthDate: infobox.BirthDate,
+		DeathDate: infobox.DeathDate,
+		Infobox:   infobox,
+	}
+}
+
+func firstParagraph(extract string) string {
+	for _, paragraph := range strings.Split(extract, "\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			return paragraph
+		}
+	}
+
+	return ""
+}
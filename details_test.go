@@ -0,0 +1,287 @@
+package shortdescription_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+const (
+	testExtract   = "Yoshua Bengio is a Canadian computer scientist."
+	testThumbnail = "https://upload.wikimedia.org/Yoshua_Bengio.jpg"
+	testURL       = "https://en.wikipedia.org/wiki/Yoshua_Bengio"
+	testInfobox   = "{{Short description|" + testDescription + "}}\n" +
+		"{{Infobox scientist\n| name = Yoshua Bengio\n| birth_date = {{birth date and age|1964|3|5}}\n" +
+		"| birth_place = [[Paris]], France <!-- city -->\n| death_date = \n}}"
+)
+
+var testInfoboxFacts = shortdescription.Infobox{
+	Type:       "scientist",
+	Name:       testPerson,
+	BirthDate:  "1964-03-05",
+	BirthPlace: "Paris, France",
+}
+
+// detailsUpstream answers the wikitext lookups and the details queries, reporting the
+// properties requested by the latter.
+func detailsUpstream(props *[]string) handlerClient {
+	return func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+
+		if query.Get("rvlimit") != "" {
+			_, _ = w.Write([]byte(testInfobox))
+			return
+		}
+
+		*props = append(*props, query.Get("prop"))
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"query": map[string]any{"pages": []any{map[string]any{
+			"title":        testPerson,
+			"extract":      "\n" + testExtract + "\nHe is known for his work on deep learning.",
+			"thumbnail":    map[string]any{"source": testThumbnail},
+			"canonicalurl": testURL,
+			"revisions":    []any{map[string]any{"slots": map[string]any{"main": map[string]any{"content": testInfobox}}}},
+		}}}})
+	}
+}
+
+func TestDetails(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		props []string
+		calls int
+	)
+
+	upstream := detailsUpstream(&props)
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			calls++
+			upstream(w, req)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name          string
+		fields        []shortdescription.Field
+		expected      shortdescription.ShortDescription
+		expectedProps []string
+		expectedErr   error
+	}{
+		{
+			name:        "fails with an unknown field",
+			fields:      []shortdescription.Field{"height"},
+			expectedErr: shortdescription.ErrInvalidArgument,
+		},
+		{
+			name:   "only the requested properties are fetched",
+			fields: []shortdescription.Field{shortdescription.FieldExtract, shortdescription.FieldURL},
+			expected: shortdescription.ShortDescription{
+				Person:      testPerson,
+				Description: testDescription,
+				Extract:     testExtract,
+				URL:         testURL,
+			},
+			expectedProps: []string{"extracts|info"},
+		},
+		{
+			// the infobox was not looked for when the description was, as it was not needed
+			name:   "cached fields are not fetched again",
+			fields: []shortdescription.Field{shortdescription.FieldExtract, shortdescription.FieldDates},
+			expected: shortdescription.ShortDescription{
+				Person:      testPerson,
+				Description: testDescription,
+				Extract:     testExtract,
+				BirthDate:   "1964-03-05",
+			},
+			expectedProps: []string{"revisions"},
+		},
+		{
+			name:   "all fields",
+			fields: shortdescription.Fields,
+			expected: shortdescription.ShortDescription{
+				Person:      testPerson,
+				Description: testDescription,
+				Extract:     testExtract,
+				Thumbnail:   testThumbnail,
+				BirthDate:   "1964-03-05",
+				URL:         testURL,
+				Infobox:     &testInfoboxFacts,
+			},
+			expectedProps: []string{"pageimages|revisions"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			props, calls = nil, 0
+
+			descr, err := descriptor.ShortDescription(ctx, testPerson, testUserAgent, shortdescription.WithFields(tc.fields...))
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("wanted %v, got %v", tc.expectedErr, err)
+			}
+
+			if err != nil && calls > 0 {
+				t.Errorf("wanted to fail before looking anything up, got %d upstream requests", calls)
+			}
+
+			if !reflect.DeepEqual(descr, tc.expected) {
+				t.Errorf("wanted %+v, got %+v", tc.expected, descr)
+			}
+
+			if len(props) != len(tc.expectedProps) || (len(props) > 0 && props[0] != tc.expectedProps[0]) {
+				t.Errorf("wanted %v to be fetched, got %v", tc.expectedProps, props)
+			}
+		})
+	}
+}
+
+func TestDetailsHandler(t *testing.T) {
+	var props []string
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  detailsUpstream(&props),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := startTestServer(t, descriptor)
+
+	testCases := []struct {
+		name         string
+		fields       string
+		expectedCode int
+		expected     shortdescription.ShortDescription
+	}{
+		{
+			name:         "unknown field",
+			fields:       "thumbnail,height",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "check result",
+			fields:       "thumbnail, url",
+			expectedCode: http.StatusOK,
+			expected: shortdescription.ShortDescription{
+				Person:      testPerson,
+				Description: testDescription,
+				Thumbnail:   testThumbnail,
+				URL:         testURL,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := client.Get(client.url + "?person=" + url.QueryEscape(testPerson) + "&fields=" + url.QueryEscape(tc.fields))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer res.Body.Close()
+
+			if res.StatusCode != tc.expectedCode {
+				t.Fatalf("wanted %v, got %v: %v", tc.expectedCode, res.StatusCode, responseError(res))
+			}
+
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+
+			var result shortdescription.ShortDescription
+
+			if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+				t.Fatal("json decoding failed", err)
+			}
+
+			if result != tc.expected {
+				t.Errorf("wanted %+v, got %+v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestDetailsWithoutWikitext(t *testing.T) {
+	var props []string
+
+	upstream := detailsUpstream(&props)
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		Backend:     shortdescription.BackendSummary,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Query().Get("action") == "" {
+				_, _ = w.Write([]byte(`{"title":"Yoshua Bengio","description":"` + testDescription + `","description_source":"local"}`))
+				return
+			}
+
+			upstream(w, req)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	descr, err := descriptor.ShortDescription(context.Background(), testPerson, testUserAgent,
+		shortdescription.WithFields(shortdescription.FieldInfobox, shortdescription.FieldDates))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(descr.Infobox, &testInfoboxFacts) || descr.BirthDate != testInfoboxFacts.BirthDate {
+		t.Errorf("wanted %+v, got %+v", testInfoboxFacts, descr)
+	}
+
+	if len(props) != 1 || props[0] != "revisions" {
+		t.Errorf("wanted the wikitext to be fetched once, got %v", props)
+	}
+}
+
+func TestDetailsInfoboxBeforeDescription(t *testing.T) {
+	var props []string
+
+	upstream := detailsUpstream(&props)
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Query().Get("rvlimit") != "" {
+				_, _ = w.Write([]byte("{{Infobox scientist\n| name = Yoshua Bengio\n}}\n" +
+					"{{Short description|" + testDescription + "}}"))
+				return
+			}
+
+			upstream(w, req)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	descr, err := descriptor.ShortDescription(context.Background(), testPerson, testUserAgent,
+		shortdescription.WithFields(shortdescription.FieldInfobox, shortdescription.FieldDates))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(descr.Infobox, &testInfoboxFacts) || descr.BirthDate != testInfoboxFacts.BirthDate {
+		t.Errorf("wanted %+v, got %+v", testInfoboxFacts, descr)
+	}
+
+	if len(props) != 1 || props[0] != "revisions" {
+		t.Errorf("wanted the wikitext to be fetched again, got %v", props)
+	}
+}
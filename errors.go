@@ -12,6 +12,9 @@ var (
 	ErrNotFound        = errors.New("not found")
 	ErrInvalidArgument = errors.New("invalid argument")
 	ErrInternal        = errors.New("internal error")
+	ErrUnavailable     = errors.New("unavailable")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrRateLimited     = errors.New("rate limited")
 )
 
 func responseError(r *http.Response) error {
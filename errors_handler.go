@@ -0,0 +1,140 @@
+package shortdescription
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrorResponse is the body of the error responses of ServeHTTP.
+type ErrorResponse struct {
+	Person    string `json:"person,omitempty"`    // as sent by the client
+	Prefix    string `json:"prefix,omitempty"`    // as sent by the client, for suggestions
+	Code      string `json:"code"`                // stable, such as "not_found"
+	Error     string `json:"error"`               // human readable
+	RequestID string `json:"requestId,omitempty"` // to be quoted when reporting the error
+}
+
+// ProblemDetails is the body of the error responses of ServeHTTP in the RFC 7807
+// application/problem+json format. See Config.ProblemJSON.
+type ProblemDetails struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	Code      string `json:"code"`
+	Person    string `json:"person,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+var (
+	errMethodNotAllowed = errors.New("method not allowed")
+	errUnknownPath      = errors.New("unknown path")
+)
+
+// errorStatuses maps the errors to their HTTP status and stable code. The first match wins.
+var errorStatuses = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{ErrInvalidArgument, http.StatusBadRequest, "invalid_argument"},
+	{ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{errMethodNotAllowed, http.StatusMethodNotAllowed, "method_not_allowed"},
+	{errUnknownPath, http.StatusNotFound, "unknown_path"},
+	{ErrNotFound, http.StatusNotFound, "not_found"},
+	{ErrRateLimited, http.StatusTooManyRequests, "rate_limited"},
+	{ErrUnavailable, http.StatusServiceUnavailable, "upstream_unavailable"},
+	{ErrUpstream, http.StatusBadGateway, "upstream_error"},
+}
+
+// errorMessages replace the messages of the errors that could leak internal details.
+var errorMessages = map[string]string{
+	"not_found":            "short description not found",
+	"upstream_unavailable": "Wikipedia is busy right now, please try again later",
+	"upstream_error":       "Wikipedia could not be reached",
+	"internal":             "internal error",
+}
+
+// publicMessage is the message of err that clients can be shown, which hides the details of
+// upstream and internal errors.
+func publicMessage(err error) string {
+	_, code := errorCode(err)
+
+	if message, ok := errorMessages[code]; ok {
+		return message
+	}
+
+	return err.Error()
+}
+
+func errorCode(err error) (int, string) {
+	for _, s := range errorStatuses {
+		if errors.Is(err, s.err) {
+			return s.status, s.code
+		}
+	}
+
+	return http.StatusInternalServerError, "internal"
+}
+
+// writeError answers with err in a JSON envelope, or as problem details if they are
+// configured or accepted by the client.
+func (d Describer) writeError(w http.ResponseWriter, req *http.Request, err error) {
+	d.writePersonError(w, req, req.URL.Query().Get("person"), err)
+}
+
+// writePersonError is writeError for the requests about person, as sent by the client.
+func (d Describer) writePersonError(w http.ResponseWriter, req *http.Request, person string, err error) {
+	status, code := errorCode(err)
+
+	recordRequest(req.Context(), func(stats *requestStats) { stats.err = err })
+
+	requestID, _ := RequestIDFromContext(req.Context())
+
+	message := publicMessage(err)
+
+	query := req.URL.Query()
+
+	var body any = ErrorResponse{
+		Person:    person,
+		Prefix:    query.Get("prefix"),
+		Code:      code,
+		Error:     message,
+		RequestID: requestID,
+	}
+
+	contentType := "application/json"
+
+	if d.problemJSON || strings.Contains(req.Header.Get("Accept"), "application/problem+json") {
+		contentType = "application/problem+json"
+		body = ProblemDetails{
+			Type:      "about:blank",
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    message,
+			Code:      code,
+			Person:    person,
+			Prefix:    query.Get("prefix"),
+			RequestID: requestID,
+		}
+	}
+
+	// let clients know when the Wikimedia APIs stop asking us to back off, as with 429s
+	if status == http.StatusServiceUnavailable {
+		if paused := d.limiter.paused(); paused > 0 {
+			w.Header().Set("Retry-After", ceilSeconds(paused))
+		}
+	}
+
+	// the format of errors is negotiated too, so shared caches must tell them apart
+	vary(w.Header(), "Accept")
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
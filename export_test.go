@@ -0,0 +1,16 @@
+package shortdescription
+
+import "sort"
+
+// Routes lists the routes a Handler serves, as reported to Metrics, for the tests of the
+// shortdescription_test package.
+func Routes() []string {
+	all := []string{route("/v1/descriptions/{person}")}
+	for path := range routes {
+		all = append(all, path)
+	}
+
+	sort.Strings(all)
+
+	return all
+}
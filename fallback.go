@@ -0,0 +1,71 @@
+package shortdescription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// SourceWikidata marks descriptions that come from Wikidata instead of the Wikipedia page.
+const SourceWikidata = "wikidata"
+
+// centralDescriptionURL asks for the description held by Wikidata, the "central" source,
+// instead of the one set locally by the page.
+const centralDescriptionURL apiURL = "https://en.wikipedia.org/w/api.php?action=query&prop=description&descprefersource=central&formatversion=2&format=json&titles="
+
+func getCentralDescriptionURL(title string) string {
+	return string(centralDescriptionURL) + url.QueryEscape(title)
+}
+
+type descriptionResponse struct {
+	Query struct {
+		Pages []struct {
+			Title             string `json:"title"`
+			Missing           bool   `json:"missing"`
+			Description       string `json:"description"`
+			DescriptionSource string `json:"descriptionsource"`
+			LastRevID         int64  `json:"lastrevid"` // with prop=info
+		} `json:"pages"`
+	} `json:"query"`
+}
+
+// wikidataDescription is the fallback for pages whose short description is "none".
+// If Wikidata has no description either, the page is still reported as having none.
+// The cache entry it comes from is taken into account by fresh.
+func (d Describer) wikidataDescription(ctx context.Context, person, userAgent string, fresh *freshness) (ShortDescription, error) {
+	text, added, ok := d.fallbackCache.getEntry(person)
+	if !ok {
+		res, err := d.fetch(ctx, getCentralDescriptionURL(person), userAgent)
+		if err != nil {
+			return ShortDescription{}, err
+		}
+
+		defer res.Body.Close()
+
+		var dr descriptionResponse
+		if err := json.NewDecoder(res.Body).Decode(&dr); err != nil {
+			return ShortDescription{}, fmt.Errorf("%w: cannot decode description response: %v", ErrUpstream, err)
+		}
+
+		for _, page := range dr.Query.Pages {
+			text = page.Description
+		}
+
+		added = d.fallbackCache.Add(person, text)
+	}
+
+	fresh.cachedUntil(d.fallbackCache.expiry(added))
+
+	descr := ShortDescription{
+		Person:      person,
+		Description: text,
+		None:        true,
+	}
+
+	if text != "" {
+		descr.Source = SourceWikidata
+	}
+
+	return descr, nil
+}
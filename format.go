@@ -0,0 +1,102 @@
+package shortdescription
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// format is a representation of a ShortDescription, as chosen with the "format" query
+// parameter or the Accept header.
+type format string
+
+const (
+	formatJSON format = "json"
+	formatText format = "text"
+	formatXML  format = "xml"
+	formatHTML format = "html"
+)
+
+// mediaTypes are the media types of each format, the first one being the one answered.
+var mediaTypes = []struct {
+	mediaType string
+	format    format
+}{
+	{"application/json", formatJSON},
+	{"text/plain", formatText},
+	{"application/xml", formatXML},
+	{"text/xml", formatXML},
+	{"text/html", formatHTML},
+}
+
+// negotiateFormat picks the format of the response. The "format" query parameter takes
+// precedence over the Accept header, and JSON is used when nothing else is acceptable.
+func negotiateFormat(req *http.Request) (format, error) {
+	if f := req.URL.Query().Get("format"); f != "" {
+		switch format(f) {
+		case formatJSON, formatText, formatXML, formatHTML:
+			return format(f), nil
+		}
+
+		return "", fmt.Errorf("%w: the 'format' query parameter only accepts json, text, xml or html", ErrInvalidArgument)
+	}
+
+	best, bestQ := formatJSON, 0.0
+
+	for _, accepted := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		// on ties, the first media type of the header wins
+		if q <= bestQ {
+			continue
+		}
+
+		for _, m := range mediaTypes {
+			if mediaType == m.mediaType || mediaType == "*/*" ||
+				strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(m.mediaType, strings.TrimSuffix(mediaType, "*")) {
+				best, bestQ = m.format, q
+				break
+			}
+		}
+	}
+
+	return best, nil
+}
+
+// render encodes descr in format f, returning the encoded body and its content type.
+func render(descr ShortDescription, f format) ([]byte, string, error) {
+	switch f {
+	case formatText:
+		return []byte(descr.Description + "\n"), "text/plain; charset=utf-8", nil
+	case formatXML:
+		body, err := xml.Marshal(struct {
+			XMLName xml.Name `xml:"shortDescription"`
+			ShortDescription
+		}{ShortDescription: descr})
+
+		return append([]byte(xml.Header), append(body, '\n')...), "application/xml; charset=utf-8", err
+	case formatHTML:
+		snippet := fmt.Sprintf(`<span class="short-description" data-person="%s" lang="en">%s</span>`+"\n",
+			html.EscapeString(descr.Person), html.EscapeString(descr.Description))
+
+		return []byte(snippet), "text/html; charset=utf-8", nil
+	default:
+		body, err := json.Marshal(descr)
+		return append(body, '\n'), "application/json", err
+	}
+}
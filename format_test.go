@@ -0,0 +1,149 @@
+package shortdescription_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+func TestFormats(t *testing.T) {
+	const (
+		person  = `Tom & "Jerry"`
+		content = "...{{Short description|Cat & mouse < dog}}..."
+	)
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  &mockHttpClient{body: content},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := shortdescription.NewHandler(descriptor)
+
+	testCases := []struct {
+		name                string
+		format              string
+		accept              string
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "JSON by default",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `{"person":"Tom \u0026 \"Jerry\"","description":"Cat \u0026 mouse \u003c dog"}` + "\n",
+		},
+		{
+			name:                "plain text",
+			accept:              "text/plain",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "Cat & mouse < dog\n",
+		},
+		{
+			name:                "XML",
+			accept:              "text/xml",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/xml; charset=utf-8",
+			expectedBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<shortDescription><person>Tom &amp; &#34;Jerry&#34;</person>` +
+				`<description>Cat &amp; mouse &lt; dog</description></shortDescription>` + "\n",
+		},
+		{
+			name:                "escaped HTML snippet",
+			format:              "html",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody: `<span class="short-description" data-person="Tom &amp; &#34;Jerry&#34;" lang="en">` +
+				`Cat &amp; mouse &lt; dog</span>` + "\n",
+		},
+		{
+			name:                "preferred by quality",
+			accept:              "text/html;q=0.5, text/plain;q=0.9, application/json;q=0.1",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "Cat & mouse < dog\n",
+		},
+		{
+			name:                "wildcard",
+			accept:              "text/*",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "Cat & mouse < dog\n",
+		},
+		{
+			name:                "format over Accept",
+			format:              "text",
+			accept:              "application/xml",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "Cat & mouse < dog\n",
+		},
+		{
+			name:                "unacceptable falls back to JSON",
+			accept:              "image/png",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+		},
+		{
+			name:         "unknown format",
+			format:       "yaml",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	etags := map[string]string{}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := url.Values{"person": {person}}
+			if tc.format != "" {
+				query.Set("format", tc.format)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/descriptions?"+query.Encode(), nil)
+			req.Header.Set("User-Agent", testUserAgent)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("wanted %v, got %v: %s", tc.expectedCode, w.Code, w.Body)
+			}
+
+			// errors are negotiated too
+			if vary := w.Header().Values("Vary"); !contains(vary, "Accept") {
+				t.Errorf("wanted the response to vary by Accept, got %q", vary)
+			}
+
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+
+			if contentType := w.Header().Get("Content-Type"); contentType != tc.expectedContentType {
+				t.Errorf("wanted a %q content type, got %q", tc.expectedContentType, contentType)
+			}
+
+			if tc.expectedBody != "" && w.Body.String() != tc.expectedBody {
+				t.Errorf("wanted %q, got %q", tc.expectedBody, w.Body)
+			}
+
+			// each representation must be validated on its own
+			etag := w.Header().Get("ETag")
+			if other, ok := etags[etag]; ok && other != tc.expectedContentType {
+				t.Errorf("the %s and %s representations share the ETag %s", other, tc.expectedContentType, etag)
+			}
+
+			etags[etag] = tc.expectedContentType
+		})
+	}
+}
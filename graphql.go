@@ -0,0 +1,567 @@
+package shortdescription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxGraphQLBody is the size of the biggest GraphQL request accepted.
+const maxGraphQLBody = 1 << 20
+
+// maxConcurrentPeople limits the lookups of optional fields made at once for people.
+const maxConcurrentPeople = 10
+
+// graphQLFields maps the GraphQL fields that are fetched on request to their Field.
+var graphQLFields = map[string]Field{
+	"extract":   FieldExtract,
+	"thumbnail": FieldThumbnail,
+	"birthDate": FieldDates,
+	"deathDate": FieldDates,
+	"url":       FieldURL,
+	"infobox":   FieldInfobox,
+}
+
+type userAgentKey struct{}
+
+// newGraphQLSchema builds the schema served at /graphql:
+//
+//	person(title: String!, wikidataFallback: Boolean): ShortDescription
+//	people(titles: [String!]!, wikidataFallback: Boolean): [ShortDescription]!
+func newGraphQLSchema(d Describer) (graphql.Schema, error) {
+	infoboxParam := graphql.NewObject(graphql.ObjectConfig{
+		Name: "InfoboxParam",
+		Fields: graphql.Fields{
+			"name":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"value": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	stringList := graphql.NewList(graphql.NewNonNull(graphql.String))
+
+	infobox := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Infobox",
+		Description: "The facts found in the infobox of a person's page, as plain text.",
+		Fields: graphql.Fields{
+			"type":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"name":         &graphql.Field{Type: graphql.String},
+			"occupation":   &graphql.Field{Type: stringList},
+			"nationality":  &graphql.Field{Type: graphql.String},
+			"birthDate":    &graphql.Field{Type: graphql.String},
+			"birthPlace":   &graphql.Field{Type: graphql.String},
+			"deathDate":    &graphql.Field{Type: graphql.String},
+			"deathPlace":   &graphql.Field{Type: graphql.String},
+			"notableWorks": &graphql.Field{Type: stringList},
+			"other": &graphql.Field{
+				Type:        graphql.NewList(graphql.NewNonNull(infoboxParam)),
+				Description: "The rest of the parameters, sorted by name.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					params := p.Source.(*Infobox).Other
+					if len(params) == 0 {
+						return nil, nil
+					}
+
+					var other []map[string]string
+					for _, name := range params.names() {
+						other = append(other, map[string]string{"name": name, "value": params[name]})
+					}
+
+					return other, nil
+				},
+			},
+		},
+	})
+
+	shortDescription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ShortDescription",
+		Fields: graphql.Fields{
+			"person":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.String},
+			"none":        &graphql.Field{Type: graphql.Boolean},
+			"source":      &graphql.Field{Type: graphql.String},
+			"extract":     &graphql.Field{Type: graphql.String},
+			"thumbnail":   &graphql.Field{Type: graphql.String},
+			"birthDate":   &graphql.Field{Type: graphql.String},
+			"deathDate":   &graphql.Field{Type: graphql.String},
+			"url":         &graphql.Field{Type: graphql.String},
+			"infobox":     &graphql.Field{Type: infobox},
+		},
+	})
+
+	wikidataFallback := &graphql.ArgumentConfig{
+		Type:        graphql.Boolean,
+		Description: "Use the Wikidata description of the pages that explicitly have no short description.",
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"person": &graphql.Field{
+				Type: shortDescription,
+				Args: graphql.FieldConfigArgument{
+					"title":            &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"wikidataFallback": wikidataFallback,
+				},
+				Resolve: d.resolvePerson,
+			},
+			"people": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(shortDescription)),
+				Description: "The short descriptions of up to 50 people, null for those without one.",
+				Args: graphql.FieldConfigArgument{
+					"titles":           &graphql.ArgumentConfig{Type: graphql.NewNonNull(stringList)},
+					"wikidataFallback": wikidataFallback,
+				},
+				Resolve: d.resolvePeople,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+func (d Describer) resolvePerson(p graphql.ResolveParams) (any, error) {
+	title, _ := p.Args["title"].(string)
+	userAgent, _ := p.Context.Value(userAgentKey{}).(string)
+
+	// ShortDescription unescapes the person, as it comes in query parameters
+	descr, err := d.ShortDescription(p.Context, url.QueryEscape(title), userAgent, graphQLOptions(p)...)
+	if err != nil {
+		return nil, graphQLError{err}
+	}
+
+	return &descr, nil
+}
+
+// resolvePeople looks up the short descriptions in a batch, and then their optional fields
+// if any was selected. People whose fields cannot be looked up are null, with an error
+// whose path points at them.
+func (d Describer) resolvePeople(p graphql.ResolveParams) (any, error) {
+	var titles []string
+	for _, title := range p.Args["titles"].([]any) {
+		titles = append(titles, title.(string))
+	}
+
+	userAgent, _ := p.Context.Value(userAgentKey{}).(string)
+
+	batch, err := d.ShortDescriptions(p.Context, titles, userAgent)
+	if err != nil {
+		return nil, graphQLError{err}
+	}
+
+	errs := make([]error, len(batch.Descriptions))
+
+	if opts := graphQLOptions(p); len(opts) > 0 {
+		var g errgroup.Group
+		g.SetLimit(maxConcurrentPeople)
+
+		for i := range batch.Descriptions {
+			i := i
+
+			g.Go(func() error {
+				descr := &batch.Descriptions[i]
+
+				withOptions, err := d.ShortDescription(p.Context, url.QueryEscape(descr.Person), userAgent, opts...)
+				if err != nil {
+					errs[i] = err
+					return nil
+				}
+
+				*descr = withOptions
+
+				return nil
+			})
+		}
+
+		_ = g.Wait()
+	}
+
+	found := make(map[string]any, len(batch.Descriptions))
+
+	for i := range batch.Descriptions {
+		descr, err := &batch.Descriptions[i], errs[i]
+
+		if err != nil {
+			// resolved as a thunk, so that the error is reported at the path of the person
+			found[descr.Person] = func() (any, error) { return nil, graphQLError{err} }
+			continue
+		}
+
+		found[descr.Person] = descr
+	}
+
+	people := make([]any, len(titles))
+	for i, title := range titles {
+		people[i] = found[normalizeTitle(title)]
+	}
+
+	return people, nil
+}
+
+// graphQLOptions are the options that fetch what the query selected of a ShortDescription.
+func graphQLOptions(p graphql.ResolveParams) []Option {
+	var opts []Option
+
+	if fallback, _ := p.Args["wikidataFallback"].(bool); fallback {
+		opts = append(opts, WithWikidataFallback())
+	}
+
+	var fields []Field
+
+	seen := map[Field]bool{}
+
+	for _, field := range p.Info.FieldASTs {
+		for _, name := range selectedFields(field.SelectionSet, p.Info.Fragments) {
+			if f, ok := graphQLFields[name]; ok && !seen[f] {
+				seen[f] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		opts = append(opts, WithFields(fields...))
+	}
+
+	return opts
+}
+
+// selectedFields lists the names of the fields of a selection set, through its fragments.
+func selectedFields(set *ast.SelectionSet, fragments map[string]ast.Definition) []string {
+	if set == nil {
+		return nil
+	}
+
+	var names []string
+
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			names = append(names, selection.Name.Value)
+		case *ast.InlineFragment:
+			names = append(names, selectedFields(selection.SelectionSet, fragments)...)
+		case *ast.FragmentSpread:
+			if fragment, ok := fragments[selection.Name.Value].(*ast.FragmentDefinition); ok {
+				names = append(names, selectedFields(fragment.SelectionSet, fragments)...)
+			}
+		}
+	}
+
+	return names
+}
+
+// graphQLError carries the stable code of an error in the "extensions" of a GraphQL error.
+type graphQLError struct {
+	err error
+}
+
+func (e graphQLError) Error() string {
+	_, code := errorCode(e.err)
+
+	if message, ok := errorMessages[code]; ok {
+		return message
+	}
+
+	return e.err.Error()
+}
+
+func (e graphQLError) Extensions() map[string]any {
+	_, code := errorCode(e.err)
+	return map[string]any{"code": code}
+}
+
+// graphQLRequest is a GraphQL request, as sent in a POST body or in GET query parameters.
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func (h Handler) serveGraphQL(w http.ResponseWriter, req *http.Request) {
+	var gr graphQLRequest
+
+	switch req.Method {
+	case http.MethodGet:
+		query := req.URL.Query()
+		gr.Query = query.Get("query")
+		gr.OperationName = query.Get("operationName")
+
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &gr.Variables); err != nil {
+				writeGraphQLError(w, fmt.Errorf("%w: the variables are not a JSON object: %v", ErrInvalidArgument, err))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxGraphQLBody)).Decode(&gr); err != nil {
+			writeGraphQLError(w, fmt.Errorf("%w: the request is not a GraphQL JSON request: %v", ErrInvalidArgument, err))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		h.d.writeError(w, req, fmt.Errorf("%w: only GET and POST requests are accepted", errMethodNotAllowed))
+
+		return
+	}
+
+	if gr.Query == "" {
+		writeGraphQLError(w, fmt.Errorf("%w: the query cannot be empty", ErrInvalidArgument))
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: gr.Query})
+	if err != nil {
+		writeGraphQLErrors(w, http.StatusBadRequest, gqlerrors.FormatErrors(err))
+		return
+	}
+
+	schema := *h.d.graphQL
+
+	if validation := graphql.ValidateDocument(&schema, doc, nil); !validation.IsValid {
+		writeGraphQLErrors(w, http.StatusBadRequest, validation.Errors)
+		return
+	}
+
+	lookups, err := h.d.graphQLLimits.check(doc, gr.OperationName, gr.Variables)
+	if err != nil {
+		writeGraphQLError(w, err)
+		return
+	}
+
+	// the request itself paid for the first lookup
+	if lookups > 1 {
+		if err := h.d.charge(req.Context(), lookups-1); err != nil {
+			writeGraphQLError(w, err)
+			return
+		}
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        schema,
+		AST:           doc,
+		OperationName: gr.OperationName,
+		Args:          gr.Variables,
+		Context:       context.WithValue(req.Context(), userAgentKey{}, req.UserAgent()),
+	})
+
+	for i := range result.Errors {
+		if result.Errors[i].Extensions == nil {
+			result.Errors[i].Extensions = lostExtensions(result.Errors[i].OriginalError())
+		}
+	}
+
+	writeGraphQLResult(w, http.StatusOK, result)
+}
+
+// lostExtensions finds the extensions of a graphQLError that graphql-go wrapped without
+// keeping them, as it does with the errors of thunks such as the people that failed.
+func lostExtensions(err error) map[string]any {
+	for err != nil {
+		switch e := err.(type) {
+		case graphQLError:
+			return e.Extensions()
+		case gqlerrors.FormattedError:
+			err = e.OriginalError()
+		case *gqlerrors.Error:
+			err = e.OriginalError
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// writeGraphQLError answers with an error found before the query could be executed.
+func writeGraphQLError(w http.ResponseWriter, err error) {
+	status, _ := errorCode(err)
+	e := graphQLError{err}
+
+	writeGraphQLErrors(w, status, []gqlerrors.FormattedError{{
+		Message:    e.Error(),
+		Locations:  []location.SourceLocation{},
+		Extensions: e.Extensions(),
+	}})
+}
+
+// writeGraphQLErrors answers without data, as the query could not be executed.
+func writeGraphQLErrors(w http.ResponseWriter, status int, errs []gqlerrors.FormattedError) {
+	writeGraphQLResult(w, status, struct {
+		Errors []gqlerrors.FormattedError `json:"errors"`
+	}{errs})
+}
+
+func writeGraphQLResult(w http.ResponseWriter, status int, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// graphQLLimits guard the GraphQL endpoint from queries that are too deep, or that select
+// too many fields.
+type graphQLLimits struct {
+	maxDepth      int
+	maxComplexity int
+}
+
+// check measures the operation of doc that will be executed. Each field counts once towards
+// the complexity, except for the fields of people, which count once per title. It returns
+// the lookups the operation may make, so that the client can be charged for them.
+func (l graphQLLimits) check(doc *ast.Document, operationName string, variables map[string]any) (int, error) {
+	fragments := map[string]*ast.FragmentDefinition{}
+
+	var operation *ast.OperationDefinition
+
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || definition.Name != nil && definition.Name.Value == operationName {
+				operation = definition
+			}
+		}
+	}
+
+	if operation == nil {
+		return 0, fmt.Errorf("%w: unknown operation %q", ErrInvalidArgument, operationName)
+	}
+
+	depth, complexity := measure(operation.SelectionSet, fragments, variables, 1)
+
+	if depth > l.maxDepth {
+		return 0, fmt.Errorf("%w: the query is %d fields deep, over the limit of %d", ErrInvalidArgument, depth, l.maxDepth)
+	}
+
+	if complexity > l.maxComplexity {
+		return 0, fmt.Errorf("%w: the query has a complexity of %d, over the limit of %d", ErrInvalidArgument, complexity, l.maxComplexity)
+	}
+
+	return lookups(operation.SelectionSet, fragments, variables), nil
+}
+
+// lookups counts the lookups the root fields of a selection set may make: one for each
+// person and people field, and one more per person whose optional fields are selected.
+func lookups(set *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, variables map[string]any) int {
+	n := 0
+
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			switch selection.Name.Value {
+			case "person":
+				n++
+				if selectsOptional(selection.SelectionSet, fragments) {
+					n++
+				}
+			case "people":
+				n++
+				if selectsOptional(selection.SelectionSet, fragments) {
+					n += titlesCount(selection, variables)
+				}
+			}
+		case *ast.InlineFragment:
+			n += lookups(selection.SelectionSet, fragments, variables)
+		case *ast.FragmentSpread:
+			if fragment, ok := fragments[selection.Name.Value]; ok {
+				n += lookups(fragment.SelectionSet, fragments, variables)
+			}
+		}
+	}
+
+	return n
+}
+
+// selectsOptional tells whether a selection set of ShortDescription selects any of the
+// fields that are fetched on request.
+func selectsOptional(set *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition) bool {
+	if set == nil {
+		return false
+	}
+
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if _, ok := graphQLFields[selection.Name.Value]; ok {
+				return true
+			}
+		case *ast.InlineFragment:
+			if selectsOptional(selection.SelectionSet, fragments) {
+				return true
+			}
+		case *ast.FragmentSpread:
+			if fragment, ok := fragments[selection.Name.Value]; ok && selectsOptional(fragment.SelectionSet, fragments) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// measure returns the depth and complexity of a selection set, whose fields count multiplier
+// times each. Fragment cycles must have been ruled out by validation.
+func measure(set *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, variables map[string]any, multiplier int) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, selection := range set.Selections {
+		var d, c int
+
+		switch selection := selection.(type) {
+		case *ast.Field:
+			n := multiplier
+			if selection.Name.Value == "people" {
+				n *= titlesCount(selection, variables)
+			}
+
+			d, c = measure(selection.SelectionSet, fragments, variables, n)
+			d, c = d+1, c+multiplier
+		case *ast.InlineFragment:
+			d, c = measure(selection.SelectionSet, fragments, variables, multiplier)
+		case *ast.FragmentSpread:
+			if fragment, ok := fragments[selection.Name.Value]; ok {
+				d, c = measure(fragment.SelectionSet, fragments, variables, multiplier)
+			}
+		}
+
+		if d > depth {
+			depth = d
+		}
+
+		complexity += c
+	}
+
+	return depth, complexity
+}
+
+// titlesCount is the amount of titles asked for by a people field, or MaxBatchSize if it
+// cannot be told.
+func titlesCount(field *ast.Field, variables map[string]any) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "titles" {
+			continue
+		}
+
+		switch value := arg.Value.(type) {
+		case *ast.ListValue:
+			return len(value.Values)
+		case *ast.Variable:
+			if titles, ok := variables[value.Name.Value].([]any); ok {
+				return len(titles)
+			}
+		}
+	}
+
+	return MaxBatchSize
+}
@@ -0,0 +1,269 @@
+package shortdescription_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+func TestGraphQL(t *testing.T) {
+	testCases := []struct {
+		name          string
+		method        string
+		query         string
+		variables     map[string]any
+		expectedCode  int
+		expectedBody  string
+		expectedProps []string // of the upstream queries
+	}{
+		{
+			name:         "only the description",
+			query:        `{ person(title: "yoshua_Bengio") { person description } }`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"data": {"person": {"person": "Yoshua Bengio", "description": "Canadian computer scientist"}}}`,
+		},
+		{
+			name: "selected fields through fragments",
+			query: `query ($title: String!) { person(title: $title) { ...card } }
+				fragment card on ShortDescription { description ... on ShortDescription { extract thumbnail } }`,
+			variables:    map[string]any{"title": testPerson},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"data": {"person": {"description": "Canadian computer scientist", ` +
+				`"extract": "Yoshua Bengio is a Canadian computer scientist.", "thumbnail": "https://upload.wikimedia.org/Yoshua_Bengio.jpg"}}}`,
+			expectedProps: []string{"extracts|pageimages"},
+		},
+		{
+			name:          "people in a batch",
+			method:        http.MethodGet,
+			query:         `{ people(titles: ["Yoshua Bengio", "Jane Doe"]) { person description url } }`,
+			expectedCode:  http.StatusOK,
+			expectedBody:  `{"data": {"people": [{"person": "Yoshua Bengio", "description": "Canadian computer scientist", "url": "https://en.wikipedia.org/wiki/Yoshua_Bengio"}, null]}}`,
+			expectedProps: []string{"revisions", "info"},
+		},
+		{
+			name:         "infobox facts",
+			query:        `{ person(title: "Yoshua Bengio") { infobox { type birthPlace other { name } } } }`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"data": {"person": {"infobox": {"type": "scientist", "birthPlace": "Paris, France", "other": null}}}}`,
+		},
+		{
+			name:         "error codes",
+			query:        `{ people(titles: []) { person } }`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"data": null, "errors": [{"message": "invalid argument: between 1 and 50 persons can be described at once", ` +
+				`"locations": [{"line": 1, "column": 3}], "path": ["people"], "extensions": {"code": "invalid_argument"}}]}`,
+		},
+		{
+			name:         "several titles in one",
+			query:        `{ people(titles: ["Yoshua Bengio|Jane Doe"]) { person } }`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"data": null, "errors": [{"message": "invalid argument: person cannot contain '|'", ` +
+				`"locations": [{"line": 1, "column": 3}], "path": ["people"], "extensions": {"code": "invalid_argument"}}]}`,
+		},
+		{
+			name:         "introspection",
+			query:        `{ __schema { queryType { fields { name type { kind ofType { name } } } } } }`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "too deep",
+			query: `{ __schema { types { fields { type { fields { type { fields { type { fields { type {
+				fields { type { name } } } } } } } } } } } } }`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"errors": [{"message": "invalid argument: the query is 13 fields deep, over the limit of 12", ` +
+				`"locations": [], "extensions": {"code": "invalid_argument"}}]}`,
+		},
+		{
+			name:         "too complex",
+			query:        `query ($titles: [String!]!) { people(titles: $titles) { person description extract thumbnail url birthDate deathDate } }`,
+			variables:    map[string]any{"titles": []string{"a", "b", "c"}},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"errors": [{"message": "invalid argument: the query has a complexity of 22, over the limit of 20", ` +
+				`"locations": [], "extensions": {"code": "invalid_argument"}}]}`,
+		},
+		{
+			name:         "syntax error",
+			query:        `{ person(title: "Yoshua Bengio") { description }`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown field",
+			query:        `{ person(title: "Yoshua Bengio") { age } }`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "empty query",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var props []string
+
+			descriptor, err := shortdescription.New(shortdescription.Config{
+				ContactInfo:          testContactInfo,
+				HttpClient:           detailsUpstream(&props),
+				GraphQLMaxComplexity: 20,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var req *http.Request
+
+			if tc.method == http.MethodGet {
+				req = httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(tc.query), nil)
+			} else {
+				body, err := json.Marshal(map[string]any{"query": tc.query, "variables": tc.variables})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				req = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+			}
+
+			req.Header.Set("User-Agent", testUserAgent)
+
+			w := httptest.NewRecorder()
+			shortdescription.NewHandler(descriptor).ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("wanted %v, got %v: %s", tc.expectedCode, w.Code, w.Body)
+			}
+
+			var body, expected any
+
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("the body is not JSON: %v", err)
+			}
+
+			if tc.expectedBody != "" {
+				if err := json.Unmarshal([]byte(tc.expectedBody), &expected); err != nil {
+					t.Fatal(err)
+				}
+
+				if !reflect.DeepEqual(body, expected) {
+					t.Errorf("wanted %s, got %s", tc.expectedBody, w.Body)
+				}
+			}
+
+			if !reflect.DeepEqual(props, tc.expectedProps) {
+				t.Errorf("wanted the upstream queries for %q, got %q", tc.expectedProps, props)
+			}
+		})
+	}
+}
+
+func TestGraphQLPeopleErrors(t *testing.T) {
+	const failing = "Jane Doe"
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			query := req.URL.Query()
+
+			if query.Get("prop") != "revisions" && query.Get("titles") == failing {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			var pages []any
+			for _, title := range strings.Split(query.Get("titles"), "|") {
+				pages = append(pages, map[string]any{
+					"title":     title,
+					"extract":   testExtract,
+					"revisions": []any{map[string]any{"slots": map[string]any{"main": map[string]any{"content": "{{Short description|" + testDescription + "}}"}}}},
+				})
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"query": map[string]any{"pages": pages}})
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := json.Marshal(map[string]any{"query": `{ people(titles: ["Yoshua Bengio", "Jane Doe"]) { person extract } }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("User-Agent", testUserAgent)
+
+	w := httptest.NewRecorder()
+	shortdescription.NewHandler(descriptor).ServeHTTP(w, req)
+
+	var result, expected any
+
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("the body is not JSON: %v", err)
+	}
+
+	expectedBody := `{"data": {"people": [{"person": "Yoshua Bengio", "extract": "` + testExtract + `"}, null]}, ` +
+		`"errors": [{"message": "Wikipedia could not be reached", "locations": [{"line": 1, "column": 3}], ` +
+		`"path": ["people", 1], "extensions": {"code": "upstream_error"}}]}`
+
+	if err := json.Unmarshal([]byte(expectedBody), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("wanted %s, got %s", expectedBody, w.Body)
+	}
+}
+
+func TestGraphQLRateLimit(t *testing.T) {
+	var props []string
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  detailsUpstream(&props),
+		ClientRate:  1e-3,
+		ClientBurst: 4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := shortdescription.NewHandler(descriptor)
+
+	testCases := []struct {
+		name         string
+		query        string
+		expectedCode int
+	}{
+		{
+			name:         "one lookup",
+			query:        `{ person(title: "Yoshua Bengio") { description } }`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			// the batch and a lookup of the extract per title, out of the 3 tokens left
+			name:         "more lookups than tokens",
+			query:        `{ people(titles: ["Yoshua Bengio", "Jane Doe", "John Doe"]) { extract } }`,
+			expectedCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(tc.query), nil)
+			req.Header.Set("User-Agent", testUserAgent)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("wanted %v, got %v: %s", tc.expectedCode, w.Code, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package grpcapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+// UnaryInterceptor admits the unary calls as the HTTP API admits its requests: clients are
+// authenticated by the API key sent in the "x-api-key" metadata and rate limited. See
+// shortdescription.Describer.Admit.
+func UnaryInterceptor(d shortdescription.Describer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, header, err := admit(ctx, d)
+
+		if len(header) > 0 {
+			_ = grpc.SetHeader(ctx, header)
+		}
+
+		if err != nil {
+			return nil, statusError(err)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor admits the streaming calls as UnaryInterceptor does the unary ones.
+func StreamInterceptor(d shortdescription.Describer) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, header, err := admit(stream.Context(), d)
+
+		if len(header) > 0 {
+			_ = stream.SetHeader(header)
+		}
+
+		if err != nil {
+			return statusError(err)
+		}
+
+		return handler(srv, admittedStream{stream, ctx})
+	}
+}
+
+// admit describes the call of ctx as an http.Request, with the metadata as headers, for
+// d.Admit. The rate limit headers are returned as metadata.
+func admit(ctx context.Context, d shortdescription.Describer) (context.Context, metadata.MD, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for name, values := range md {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		req.RemoteAddr = p.Addr.String()
+	}
+
+	header := http.Header{}
+
+	admitted, err := d.Admit(req, header)
+
+	res := metadata.MD{}
+	for name, values := range header {
+		res.Set(strings.ToLower(name), values...)
+	}
+
+	if err != nil {
+		return ctx, res, err
+	}
+
+	return admitted, res, nil
+}
+
+// admittedStream is a stream whose context is on behalf of the admitted client.
+type admittedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s admittedStream) Context() context.Context {
+	return s.ctx
+}
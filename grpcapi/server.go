@@ -0,0 +1,189 @@
+// Package grpcapi serves a shortdescription.Describer over gRPC, as defined in
+// shortdescription.proto.
+package grpcapi
+
+//go:generate buf generate shortdescription.proto
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+// Server implements ShortDescriptionServiceServer with a Describer.
+type Server struct {
+	UnimplementedShortDescriptionServiceServer
+
+	d shortdescription.Describer
+}
+
+func NewServer(d shortdescription.Describer) *Server {
+	return &Server{d: d}
+}
+
+// Register registers a Server for d on s.
+func Register(s grpc.ServiceRegistrar, d shortdescription.Describer) {
+	RegisterShortDescriptionServiceServer(s, NewServer(d))
+}
+
+func (s *Server) GetShortDescription(ctx context.Context, req *GetShortDescriptionRequest) (*ShortDescription, error) {
+	var opts []shortdescription.Option
+
+	if req.WikidataFallback {
+		opts = append(opts, shortdescription.WithWikidataFallback())
+	}
+
+	if len(req.Fields) > 0 {
+		fields := make([]shortdescription.Field, 0, len(req.Fields))
+		for _, field := range req.Fields {
+			fields = append(fields, shortdescription.Field(field))
+		}
+
+		opts = append(opts, shortdescription.WithFields(fields...))
+	}
+
+	// ShortDescription unescapes the person, as it comes in query parameters
+	descr, err := s.d.ShortDescription(ctx, url.QueryEscape(req.Person), userAgent(ctx), opts...)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return newShortDescription(descr), nil
+}
+
+func (s *Server) BatchGetShortDescriptions(ctx context.Context, req *BatchGetShortDescriptionsRequest) (*BatchGetShortDescriptionsResponse, error) {
+	batch, err := s.d.ShortDescriptions(ctx, req.Persons, userAgent(ctx))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	res := &BatchGetShortDescriptionsResponse{
+		Descriptions: make([]*ShortDescription, 0, len(batch.Descriptions)),
+		NotFound:     batch.NotFound,
+	}
+
+	for _, descr := range batch.Descriptions {
+		res.Descriptions = append(res.Descriptions, newShortDescription(descr))
+	}
+
+	return res, nil
+}
+
+func (s *Server) BulkGetShortDescriptions(req *BulkGetShortDescriptionsRequest, stream ShortDescriptionService_BulkGetShortDescriptionsServer) error {
+	ctx := stream.Context()
+
+	err := s.d.BulkPersons(ctx, req.Persons, userAgent(ctx), func(result shortdescription.BulkResult) error {
+		res := &BulkResult{Line: int32(result.Line)}
+
+		if result.Code != "" {
+			res.Result = &BulkResult_Error{Error: &Error{
+				Person:  result.Person,
+				Code:    result.Code,
+				Message: result.Error,
+			}}
+		} else {
+			res.Result = &BulkResult_Description{Description: newShortDescription(result.ShortDescription)}
+		}
+
+		return stream.Send(res)
+	})
+	if err != nil {
+		return statusError(err)
+	}
+
+	return nil
+}
+
+// userAgent is the user agent of the client, which gRPC sends in the metadata.
+func userAgent(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if ua := md.Get("user-agent"); len(ua) > 0 {
+		return ua[0]
+	}
+
+	return ""
+}
+
+// statusCodes maps the errors of the Describer to gRPC status codes. The first match wins.
+var statusCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{shortdescription.ErrInvalidArgument, codes.InvalidArgument},
+	{shortdescription.ErrUnauthorized, codes.Unauthenticated},
+	{shortdescription.ErrNotFound, codes.NotFound},
+	{shortdescription.ErrRateLimited, codes.ResourceExhausted},
+	{shortdescription.ErrUnavailable, codes.Unavailable},
+	{shortdescription.ErrUpstream, codes.Unavailable},
+	{context.Canceled, codes.Canceled},
+	{context.DeadlineExceeded, codes.DeadlineExceeded},
+}
+
+// statusMessages replace the messages of the errors that could leak internal details, as in
+// the HTTP API.
+var statusMessages = map[codes.Code]string{
+	codes.NotFound:    "short description not found",
+	codes.Unavailable: "Wikipedia could not be reached or is busy right now, please try again later",
+	codes.Internal:    "internal error",
+}
+
+func statusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err // such as when a stream could not be sent to
+	}
+
+	code := codes.Internal
+
+	for _, s := range statusCodes {
+		if errors.Is(err, s.err) {
+			code = s.code
+			break
+		}
+	}
+
+	message, ok := statusMessages[code]
+	if !ok {
+		message = err.Error()
+	}
+
+	return status.Error(code, message)
+}
+
+func newShortDescription(descr shortdescription.ShortDescription) *ShortDescription {
+	res := &ShortDescription{
+		Person:      descr.Person,
+		Description: descr.Description,
+		None:        descr.None,
+		Source:      descr.Source,
+		Extract:     descr.Extract,
+		Thumbnail:   descr.Thumbnail,
+		BirthDate:   descr.BirthDate,
+		DeathDate:   descr.DeathDate,
+		Url:         descr.URL,
+	}
+
+	if infobox := descr.Infobox; infobox != nil {
+		res.Infobox = &Infobox{
+			Type:         infobox.Type,
+			Name:         infobox.Name,
+			Occupation:   infobox.Occupation,
+			Nationality:  infobox.Nationality,
+			BirthDate:    infobox.BirthDate,
+			BirthPlace:   infobox.BirthPlace,
+			DeathDate:    infobox.DeathDate,
+			DeathPlace:   infobox.DeathPlace,
+			NotableWorks: infobox.NotableWorks,
+			Other:        infobox.Other,
+		}
+	}
+
+	return res
+}
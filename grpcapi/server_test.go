@@ -0,0 +1,281 @@
+package grpcapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+	"github.com/Inuart/wikimedia-exercise/grpcapi"
+)
+
+const (
+	testPerson      = "Yoshua Bengio"
+	testDescription = "Canadian computer scientist"
+)
+
+// upstream mimics the revisions queries of the MediaWiki API, knowing only testPerson.
+type upstream struct{}
+
+func (upstream) Do(req *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+
+	type page struct {
+		Title     string `json:"title"`
+		Missing   bool   `json:"missing,omitempty"`
+		Revisions []any  `json:"revisions,omitempty"`
+	}
+
+	var pages []page
+
+	for _, title := range strings.Split(req.URL.Query().Get("titles"), "|") {
+		if title != testPerson {
+			pages = append(pages, page{Title: title, Missing: true})
+			continue
+		}
+
+		content := "{{Short description|" + testDescription + "}}"
+		pages = append(pages, page{
+			Title:     title,
+			Revisions: []any{map[string]any{"slots": map[string]any{"main": map[string]any{"content": content}}}},
+		})
+	}
+
+	err := json.NewEncoder(w).Encode(map[string]any{"query": map[string]any{"pages": pages}})
+
+	return w.Result(), err
+}
+
+func startTestServer(t *testing.T, cfg shortdescription.Config) grpcapi.ShortDescriptionServiceClient {
+	cfg.ContactInfo = "test contact info"
+	cfg.HttpClient = upstream{}
+
+	descriptor, err := shortdescription.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcapi.UnaryInterceptor(descriptor)),
+		grpc.ChainStreamInterceptor(grpcapi.StreamInterceptor(descriptor)),
+	)
+	grpcapi.Register(server, descriptor)
+
+	go func() { _ = server.Serve(listener) }()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+	})
+
+	return grpcapi.NewShortDescriptionServiceClient(conn)
+}
+
+func TestGetShortDescription(t *testing.T) {
+	client := startTestServer(t, shortdescription.Config{})
+
+	testCases := []struct {
+		name         string
+		person       string
+		expected     *grpcapi.ShortDescription
+		expectedCode codes.Code
+	}{
+		{
+			name:     "found",
+			person:   "yoshua_Bengio",
+			expected: &grpcapi.ShortDescription{Person: testPerson, Description: testDescription},
+		},
+		{
+			name:         "not found",
+			person:       "Jane Doe",
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "empty person",
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			descr, err := client.GetShortDescription(context.Background(), &grpcapi.GetShortDescriptionRequest{Person: tc.person})
+			if code := status.Code(err); code != tc.expectedCode {
+				t.Fatalf("wanted %v, got %v", tc.expectedCode, err)
+			}
+
+			if tc.expected != nil && !proto.Equal(descr, tc.expected) {
+				t.Errorf("wanted %v, got %v", tc.expected, descr)
+			}
+		})
+	}
+}
+
+func TestBatchGetShortDescriptions(t *testing.T) {
+	client := startTestServer(t, shortdescription.Config{})
+
+	res, err := client.BatchGetShortDescriptions(context.Background(), &grpcapi.BatchGetShortDescriptionsRequest{
+		Persons: []string{testPerson, "Jane Doe"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &grpcapi.BatchGetShortDescriptionsResponse{
+		Descriptions: []*grpcapi.ShortDescription{{Person: testPerson, Description: testDescription}},
+		NotFound:     []string{"Jane Doe"},
+	}
+
+	if !proto.Equal(res, expected) {
+		t.Errorf("wanted %v, got %v", expected, res)
+	}
+
+	_, err = client.BatchGetShortDescriptions(context.Background(), &grpcapi.BatchGetShortDescriptionsRequest{})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf("wanted %v for an empty batch, got %v", codes.InvalidArgument, err)
+	}
+}
+
+func TestBulkGetShortDescriptions(t *testing.T) {
+	client := startTestServer(t, shortdescription.Config{})
+
+	stream, err := client.BulkGetShortDescriptions(context.Background(), &grpcapi.BulkGetShortDescriptionsRequest{
+		Persons: []string{testPerson, "Jane Doe", ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results []*grpcapi.BulkResult
+
+	for {
+		result, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Line < results[j].Line })
+
+	expected := []*grpcapi.BulkResult{
+		{Line: 1, Result: &grpcapi.BulkResult_Description{
+			Description: &grpcapi.ShortDescription{Person: testPerson, Description: testDescription},
+		}},
+		{Line: 2, Result: &grpcapi.BulkResult_Error{
+			Error: &grpcapi.Error{Person: "Jane Doe", Code: "not_found", Message: "short description not found"},
+		}},
+		{Line: 3, Result: &grpcapi.BulkResult_Error{
+			Error: &grpcapi.Error{Code: "invalid_argument", Message: "invalid argument: person is empty"},
+		}},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("wanted %v, got %v", expected, results)
+	}
+
+	for i := range expected {
+		if !proto.Equal(results[i], expected[i]) {
+			t.Errorf("wanted %v, got %v", expected[i], results[i])
+		}
+	}
+}
+
+// registry knows a single client, with the key "s3cr3t", which can make one request.
+type registry struct{}
+
+func (registry) Client(_ context.Context, key string) (shortdescription.Client, bool, error) {
+	if key != "s3cr3t" {
+		return shortdescription.Client{}, false, nil
+	}
+
+	return shortdescription.Client{Key: key, Name: "test", ContactInfo: "test", Rate: 1e-3, Burst: 1}, true, nil
+}
+
+func TestAdmission(t *testing.T) {
+	client := startTestServer(t, shortdescription.Config{
+		Clients:       registry{},
+		RequireAPIKey: true,
+	})
+
+	testCases := []struct {
+		name         string
+		key          string
+		expectedCode codes.Code
+	}{
+		{
+			name:         "without API key",
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "unknown API key",
+			key:          "guess",
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name: "registered client",
+			key:  "s3cr3t",
+		},
+		{
+			name:         "over the limit",
+			key:          "s3cr3t",
+			expectedCode: codes.ResourceExhausted,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.key != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", tc.key)
+			}
+
+			_, err := client.GetShortDescription(ctx, &grpcapi.GetShortDescriptionRequest{Person: testPerson})
+			if code := status.Code(err); code != tc.expectedCode {
+				t.Errorf("wanted %v, got %v", tc.expectedCode, err)
+			}
+		})
+	}
+
+	stream, err := client.BulkGetShortDescriptions(context.Background(), &grpcapi.BulkGetShortDescriptionsRequest{
+		Persons: []string{testPerson},
+	})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+
+	if code := status.Code(err); code != codes.Unauthenticated {
+		t.Errorf("wanted streams to be admitted too, got %v", err)
+	}
+}
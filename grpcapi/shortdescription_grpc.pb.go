@@ -0,0 +1,218 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: shortdescription.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ShortDescriptionService_GetShortDescription_FullMethodName       = "/shortdescription.v1.ShortDescriptionService/GetShortDescription"
+	ShortDescriptionService_BatchGetShortDescriptions_FullMethodName = "/shortdescription.v1.ShortDescriptionService/BatchGetShortDescriptions"
+	ShortDescriptionService_BulkGetShortDescriptions_FullMethodName  = "/shortdescription.v1.ShortDescriptionService/BulkGetShortDescriptions"
+)
+
+// ShortDescriptionServiceClient is the client API for ShortDescriptionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ShortDescriptionServiceClient interface {
+	GetShortDescription(ctx context.Context, in *GetShortDescriptionRequest, opts ...grpc.CallOption) (*ShortDescription, error)
+	// BatchGetShortDescriptions describes up to 50 persons at once.
+	BatchGetShortDescriptions(ctx context.Context, in *BatchGetShortDescriptionsRequest, opts ...grpc.CallOption) (*BatchGetShortDescriptionsResponse, error)
+	// BulkGetShortDescriptions describes any amount of persons, streaming each result as soon
+	// as it completes, in no particular order.
+	BulkGetShortDescriptions(ctx context.Context, in *BulkGetShortDescriptionsRequest, opts ...grpc.CallOption) (ShortDescriptionService_BulkGetShortDescriptionsClient, error)
+}
+
+type shortDescriptionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShortDescriptionServiceClient(cc grpc.ClientConnInterface) ShortDescriptionServiceClient {
+	return &shortDescriptionServiceClient{cc}
+}
+
+func (c *shortDescriptionServiceClient) GetShortDescription(ctx context.Context, in *GetShortDescriptionRequest, opts ...grpc.CallOption) (*ShortDescription, error) {
+	out := new(ShortDescription)
+	err := c.cc.Invoke(ctx, ShortDescriptionService_GetShortDescription_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortDescriptionServiceClient) BatchGetShortDescriptions(ctx context.Context, in *BatchGetShortDescriptionsRequest, opts ...grpc.CallOption) (*BatchGetShortDescriptionsResponse, error) {
+	out := new(BatchGetShortDescriptionsResponse)
+	err := c.cc.Invoke(ctx, ShortDescriptionService_BatchGetShortDescriptions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortDescriptionServiceClient) BulkGetShortDescriptions(ctx context.Context, in *BulkGetShortDescriptionsRequest, opts ...grpc.CallOption) (ShortDescriptionService_BulkGetShortDescriptionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ShortDescriptionService_ServiceDesc.Streams[0], ShortDescriptionService_BulkGetShortDescriptions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &shortDescriptionServiceBulkGetShortDescriptionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ShortDescriptionService_BulkGetShortDescriptionsClient interface {
+	Recv() (*BulkResult, error)
+	grpc.ClientStream
+}
+
+type shortDescriptionServiceBulkGetShortDescriptionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *shortDescriptionServiceBulkGetShortDescriptionsClient) Recv() (*BulkResult, error) {
+	m := new(BulkResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ShortDescriptionServiceServer is the server API for ShortDescriptionService service.
+// All implementations must embed UnimplementedShortDescriptionServiceServer
+// for forward compatibility
+type ShortDescriptionServiceServer interface {
+	GetShortDescription(context.Context, *GetShortDescriptionRequest) (*ShortDescription, error)
+	// BatchGetShortDescriptions describes up to 50 persons at once.
+	BatchGetShortDescriptions(context.Context, *BatchGetShortDescriptionsRequest) (*BatchGetShortDescriptionsResponse, error)
+	// BulkGetShortDescriptions describes any amount of persons, streaming each result as soon
+	// as it completes, in no particular order.
+	BulkGetShortDescriptions(*BulkGetShortDescriptionsRequest, ShortDescriptionService_BulkGetShortDescriptionsServer) error
+	mustEmbedUnimplementedShortDescriptionServiceServer()
+}
+
+// UnimplementedShortDescriptionServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedShortDescriptionServiceServer struct {
+}
+
+func (UnimplementedShortDescriptionServiceServer) GetShortDescription(context.Context, *GetShortDescriptionRequest) (*ShortDescription, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShortDescription not implemented")
+}
+func (UnimplementedShortDescriptionServiceServer) BatchGetShortDescriptions(context.Context, *BatchGetShortDescriptionsRequest) (*BatchGetShortDescriptionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetShortDescriptions not implemented")
+}
+func (UnimplementedShortDescriptionServiceServer) BulkGetShortDescriptions(*BulkGetShortDescriptionsRequest, ShortDescriptionService_BulkGetShortDescriptionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method BulkGetShortDescriptions not implemented")
+}
+func (UnimplementedShortDescriptionServiceServer) mustEmbedUnimplementedShortDescriptionServiceServer() {
+}
+
+// UnsafeShortDescriptionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ShortDescriptionServiceServer will
+// result in compilation errors.
+type UnsafeShortDescriptionServiceServer interface {
+	mustEmbedUnimplementedShortDescriptionServiceServer()
+}
+
+func RegisterShortDescriptionServiceServer(s grpc.ServiceRegistrar, srv ShortDescriptionServiceServer) {
+	s.RegisterService(&ShortDescriptionService_ServiceDesc, srv)
+}
+
+func _ShortDescriptionService_GetShortDescription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetShortDescriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortDescriptionServiceServer).GetShortDescription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortDescriptionService_GetShortDescription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortDescriptionServiceServer).GetShortDescription(ctx, req.(*GetShortDescriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortDescriptionService_BatchGetShortDescriptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetShortDescriptionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortDescriptionServiceServer).BatchGetShortDescriptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortDescriptionService_BatchGetShortDescriptions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortDescriptionServiceServer).BatchGetShortDescriptions(ctx, req.(*BatchGetShortDescriptionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortDescriptionService_BulkGetShortDescriptions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BulkGetShortDescriptionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShortDescriptionServiceServer).BulkGetShortDescriptions(m, &shortDescriptionServiceBulkGetShortDescriptionsServer{stream})
+}
+
+type ShortDescriptionService_BulkGetShortDescriptionsServer interface {
+	Send(*BulkResult) error
+	grpc.ServerStream
+}
+
+type shortDescriptionServiceBulkGetShortDescriptionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *shortDescriptionServiceBulkGetShortDescriptionsServer) Send(m *BulkResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ShortDescriptionService_ServiceDesc is the grpc.ServiceDesc for ShortDescriptionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ShortDescriptionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "shortdescription.v1.ShortDescriptionService",
+	HandlerType: (*ShortDescriptionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetShortDescription",
+			Handler:    _ShortDescriptionService_GetShortDescription_Handler,
+		},
+		{
+			MethodName: "BatchGetShortDescriptions",
+			Handler:    _ShortDescriptionService_BatchGetShortDescriptions_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BulkGetShortDescriptions",
+			Handler:       _ShortDescriptionService_BulkGetShortDescriptions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "shortdescription.proto",
+}
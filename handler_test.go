@@ -1,10 +1,16 @@
 package shortdescription_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"golang.org/x/sync/errgroup"
@@ -100,6 +106,330 @@ func TestDescriptorHandler(t *testing.T) {
 	}
 }
 
+func TestErrorResponses(t *testing.T) {
+	var mockClient mockHttpClient
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  &mockClient,
+		CachedTTL:   -1, // remove caching
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name         string
+		method       string
+		query        string
+		accept       string
+		upstreamBody wikiJSON
+		upstreamErr  error
+		expectedCode int
+		expectedType string
+		expected     any
+	}{
+		{
+			name:         "not found",
+			query:        "?person=Nobody",
+			upstreamBody: "{{Infobox person}}",
+			expectedCode: http.StatusNotFound,
+			expectedType: "application/json",
+			expected:     &shortdescription.ErrorResponse{Person: "Nobody", Code: "not_found", Error: "short description not found", RequestID: "test-request-id"},
+		},
+		{
+			name:         "not found in the path",
+			query:        "/v1/descriptions/Nobody%20Else",
+			upstreamBody: "{{Infobox person}}",
+			expectedCode: http.StatusNotFound,
+			expectedType: "application/json",
+			expected:     &shortdescription.ErrorResponse{Person: "Nobody Else", Code: "not_found", Error: "short description not found", RequestID: "test-request-id"},
+		},
+		{
+			name:         "method not allowed in the path",
+			method:       http.MethodPost,
+			query:        "/v1/descriptions/Yoshua_Bengio",
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedType: "application/json",
+			expected: &shortdescription.ErrorResponse{
+				Person:    "Yoshua_Bengio",
+				Code:      "method_not_allowed",
+				Error:     "method not allowed: only GET requests are accepted",
+				RequestID: "test-request-id",
+			},
+		},
+		{
+			name:         "invalid argument",
+			query:        "?person=" + url.QueryEscape(testPerson) + "&fallback=dbpedia",
+			expectedCode: http.StatusBadRequest,
+			expectedType: "application/json",
+			expected: &shortdescription.ErrorResponse{
+				Person:    testPerson,
+				Code:      "invalid_argument",
+				Error:     `invalid argument: the 'fallback' query parameter only accepts "wikidata"`,
+				RequestID: "test-request-id",
+			},
+		},
+		{
+			name:         "invalid suggestion",
+			query:        "/suggest?prefix=Yosh&limit=ten",
+			expectedCode: http.StatusBadRequest,
+			expectedType: "application/json",
+			expected: &shortdescription.ErrorResponse{
+				Prefix:    "Yosh",
+				Code:      "invalid_argument",
+				Error:     "invalid argument: the 'limit' query parameter must be a number",
+				RequestID: "test-request-id",
+			},
+		},
+		{
+			name:         "method not allowed",
+			method:       http.MethodPost,
+			query:        "?person=" + url.QueryEscape(testPerson),
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedType: "application/json",
+			expected: &shortdescription.ErrorResponse{
+				Person:    testPerson,
+				Code:      "method_not_allowed",
+				Error:     "method not allowed: only GET requests are accepted",
+				RequestID: "test-request-id",
+			},
+		},
+		{
+			name:         "upstream cannot be reached",
+			query:        "?person=" + url.QueryEscape(testPerson),
+			upstreamErr:  errors.New("dial tcp: connection refused"),
+			expectedCode: http.StatusBadGateway,
+			expectedType: "application/json",
+			expected: &shortdescription.ErrorResponse{
+				Person:    testPerson,
+				Code:      "upstream_error",
+				Error:     "Wikipedia could not be reached",
+				RequestID: "test-request-id",
+			},
+		},
+		{
+			name:         "upstream times out",
+			query:        "?person=" + url.QueryEscape(testPerson),
+			upstreamErr:  context.DeadlineExceeded,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedType: "application/json",
+			expected: &shortdescription.ErrorResponse{
+				Person:    testPerson,
+				Code:      "upstream_unavailable",
+				Error:     "Wikipedia is busy right now, please try again later",
+				RequestID: "test-request-id",
+			},
+		},
+		{
+			name:         "problem details",
+			query:        "?person=Nobody",
+			accept:       "application/problem+json",
+			upstreamBody: "{{Infobox person}}",
+			expectedCode: http.StatusNotFound,
+			expectedType: "application/problem+json",
+			expected: &shortdescription.ProblemDetails{
+				Type:      "about:blank",
+				Title:     "Not Found",
+				Status:    http.StatusNotFound,
+				Detail:    "short description not found",
+				Code:      "not_found",
+				Person:    "Nobody",
+				RequestID: "test-request-id",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient.body, mockClient.err = tc.upstreamBody, tc.upstreamErr
+
+			if tc.method == "" {
+				tc.method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(tc.method, "/"+strings.TrimPrefix(tc.query, "/"), nil)
+			req.Header.Set("User-Agent", testUserAgent)
+			req.Header.Set("Accept", tc.accept)
+			req.Header.Set("X-Request-ID", "test-request-id")
+
+			w := httptest.NewRecorder()
+			descriptor.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("wanted %v, got %v: %s", tc.expectedCode, w.Code, w.Body)
+			}
+
+			if contentType := w.Header().Get("Content-Type"); contentType != tc.expectedType {
+				t.Errorf("wanted %v, got %v", tc.expectedType, contentType)
+			}
+
+			result := reflect.New(reflect.TypeOf(tc.expected).Elem()).Interface()
+
+			if err := json.NewDecoder(w.Body).Decode(result); err != nil {
+				t.Fatal("json decoding failed", err)
+			}
+
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("wanted %+v, got %+v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestRouter(t *testing.T) {
+	var calls int32
+
+	path := filepath.Join(t.TempDir(), "clients.json")
+	writeClients(t, path, `[
+		{"key": "admin", "name": "Admin", "contactInfo": "admin@example.org", "admin": true},
+		{"key": "user", "name": "User", "contactInfo": "user@example.org"}
+	]`)
+
+	registry, err := shortdescription.NewFileClientRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  suggestUpstream(&calls, 0),
+		Clients:     registry,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mounted under a prefix
+	handler := http.StripPrefix("/api", shortdescription.NewHandler(descriptor))
+
+	testCases := []struct {
+		name         string
+		method       string
+		target       string
+		apiKey       string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "description by path",
+			target:       "/api/v1/descriptions/Yoshua_Bengio",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"person":"Yoshua Bengio","description":"` + testDescription + `"}`,
+		},
+		{
+			name:         "description by query",
+			target:       "/api/v1/descriptions?person=Yoshua+Bengio",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"person":"Yoshua Bengio","description":"` + testDescription + `"}`,
+		},
+		{
+			name:         "root alias",
+			target:       "/api/?person=Yoshua+Bengio",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"person":"Yoshua Bengio","description":"` + testDescription + `"}`,
+		},
+		{
+			name:         "batch",
+			target:       "/api/v1/batch?person=Yoshua+Bengio&person=Yoshua_Lo&person=yoshua_Bengio",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"descriptions":[{"person":"Yoshua Bengio","description":"` + testDescription + `"}],"notFound":["Yoshua Lo"]}`,
+		},
+		{
+			name:         "batch with several titles in a person",
+			target:       "/api/v1/batch?person=Yoshua+Bengio%7CYoshua_Lo",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"person":"Yoshua Bengio|Yoshua_Lo","code":"invalid_argument","error":"invalid argument: person cannot contain '|'","requestId":"test-request-id"}`,
+		},
+		{
+			name:         "suggest",
+			target:       "/api/v1/suggest?prefix=Yoshua&limit=1",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "health",
+			target:       "/api/v1/health",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"ok"}`,
+		},
+		{
+			name:         "unknown path",
+			target:       "/api/v2/descriptions/Yoshua_Bengio",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"code":"unknown_path","error":"unknown path: /v2/descriptions/Yoshua_Bengio","requestId":"test-request-id"}`,
+		},
+		{
+			name:         "wrong method",
+			method:       http.MethodPost,
+			target:       "/api/v1/batch?person=Yoshua+Bengio",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:         "admin route without an admin key",
+			method:       http.MethodDelete,
+			target:       "/api/v1/admin/cache?person=Yoshua+Bengio",
+			apiKey:       "user",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "forget a cached description",
+			method:       http.MethodDelete,
+			target:       "/api/v1/admin/cache?person=Yoshua+Bengio",
+			apiKey:       "admin",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "forget a blank person",
+			method:       http.MethodDelete,
+			target:       "/api/v1/admin/cache?person=+",
+			apiKey:       "admin",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "reload the API keys",
+			method:       http.MethodPost,
+			target:       "/api/v1/admin/reload",
+			apiKey:       "admin",
+			expectedCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.method == "" {
+				tc.method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(tc.method, tc.target, nil)
+			req.Header.Set("User-Agent", testUserAgent)
+			req.Header.Set("X-API-Key", tc.apiKey)
+			req.Header.Set("X-Request-ID", "test-request-id")
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("wanted %v, got %v: %s", tc.expectedCode, w.Code, w.Body)
+			}
+
+			if body := strings.TrimSpace(w.Body.String()); tc.expectedBody != "" && body != tc.expectedBody {
+				t.Errorf("wanted %s, got %s", tc.expectedBody, body)
+			}
+		})
+	}
+
+	// the forgotten description is looked up again
+	calls = 0
+
+	if _, err := descriptor.ShortDescription(context.Background(), testPerson, testUserAgent); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 1 {
+		t.Errorf("wanted the forgotten description to be fetched again, got %d calls", calls)
+	}
+}
+
 // Keeping integration test cases at a minimum to not overload the real service.
 func TestDescriptorIntegration(t *testing.T) {
 	if testing.Short() {
@@ -0,0 +1,200 @@
+package shortdescription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// probeTimeout bounds how long readiness checks wait for the upstream probe.
+	probeTimeout = 5 * time.Second
+	// warmUpTimeout bounds each batch looked up while warming up the cache.
+	warmUpTimeout = 30 * time.Second
+)
+
+// Statuses of a Readiness and of each of its checks.
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded" // ready, but some requests may fail or be slow
+	StatusUnavailable = "unavailable"
+)
+
+// Readiness tells whether a Describer is ready to serve requests, and why.
+type Readiness struct {
+	Status string           `json:"status"`
+	Checks map[string]Check `json:"checks"`
+}
+
+// Check is the result of one of the readiness checks.
+type Check struct {
+	Status    string     `json:"status"`
+	Detail    string     `json:"detail,omitempty"`
+	CheckedAt *time.Time `json:"checkedAt,omitempty"` // of checks whose result is kept for a while
+}
+
+// health keeps the state of the readiness checks.
+type health struct {
+	warmUpTotal int
+	warmedUp    int64 // persons looked up so far, accessed atomically
+
+	probePerson   string // empty when there's no upstream probe
+	probeInterval time.Duration
+
+	mu        sync.Mutex
+	warmUpErr error // the last error while warming up
+
+	probeMu  sync.Mutex // held while probing, so that concurrent checks wait for the same probe
+	probed   time.Time
+	probeErr error
+}
+
+func newHealth(cfg Config, warmUp []string) *health {
+	return &health{
+		warmUpTotal:   len(warmUp),
+		probePerson:   cfg.ProbePerson,
+		probeInterval: cfg.ProbeInterval,
+	}
+}
+
+// Readiness runs the readiness checks:
+//
+//   - "warmUp", when Config.WarmUp is set, is unavailable until every person in it has been
+//     looked up, whether it was found or not.
+//   - "upstreamLimiter" is degraded while the Wikimedia APIs asked to back off, or while the
+//     upstream queue is full, as requests that miss the cache would be failed or held back.
+//     There's no circuit breaker: this is the state of the upstream rate limiter, which only
+//     stops requests when the APIs ask for it, not after consecutive failures.
+//   - "upstreamProbe", when Config.ProbePerson is set, is degraded if the last lookup of that
+//     person failed for any other reason than not being found. The lookup bypasses the cache and
+//     is made again at most every Config.ProbeInterval.
+//
+// The Describer is ready unless some check is unavailable, and its status is the worst of
+// them. The upstream checks are only ever degraded, as the upstream API is shared by every
+// replica: failing them would take all of them out at once, even though the cached persons
+// could still be served.
+func (d Describer) Readiness(ctx context.Context) Readiness {
+	h := d.health
+
+	r := Readiness{Status: StatusOK, Checks: map[string]Check{}}
+
+	add := func(name string, check Check) {
+		if check.Status == StatusUnavailable || r.Status == StatusOK {
+			r.Status = check.Status
+		}
+
+		r.Checks[name] = check
+	}
+
+	if h.warmUpTotal > 0 {
+		add("warmUp", h.warmUpCheck())
+	}
+
+	add("upstreamLimiter", d.limiter.check())
+
+	if h.probePerson != "" {
+		add("upstreamProbe", d.probe(ctx))
+	}
+
+	return r
+}
+
+func (h *health) warmUpCheck() Check {
+	warmedUp := int(atomic.LoadInt64(&h.warmedUp))
+
+	h.mu.Lock()
+	err := h.warmUpErr
+	h.mu.Unlock()
+
+	check := Check{Status: StatusOK, Detail: fmt.Sprintf("%d of %d persons looked up", warmedUp, h.warmUpTotal)}
+
+	if warmedUp < h.warmUpTotal {
+		check.Status = StatusUnavailable
+	}
+
+	if err != nil {
+		check.Detail += ", some failed: " + publicMessage(err)
+	}
+
+	return check
+}
+
+// warmUp looks titles up in batches to fill the cache.
+func (d Describer) warmUp(titles []string) {
+	h := d.health
+
+	for len(titles) > 0 {
+		batch := titles
+		if len(batch) > MaxBatchSize {
+			batch = batch[:MaxBatchSize]
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), warmUpTimeout)
+		_, err := d.shortDescriptions(ctx, batch, d.userAgent)
+		cancel()
+
+		if err != nil {
+			// failing to warm up does not keep the describer from serving
+			h.mu.Lock()
+			h.warmUpErr = err
+			h.mu.Unlock()
+		}
+
+		atomic.AddInt64(&h.warmedUp, int64(len(batch)))
+		titles = titles[len(batch):]
+	}
+}
+
+// probe looks the probe person up upstream, unless it was done less than an interval ago.
+// Concurrent checks wait for the same probe.
+func (d Describer) probe(ctx context.Context) Check {
+	h := d.health
+
+	h.probeMu.Lock()
+	defer h.probeMu.Unlock()
+
+	if h.probed.IsZero() || time.Since(h.probed) >= h.probeInterval {
+		ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+		defer cancel()
+
+		_, err := d.backend.describe(ctx, h.probePerson, d.userAgent, nil)
+		if errors.Is(err, ErrNotFound) {
+			err = nil // the API answered
+		}
+
+		if err != nil && ctx.Err() != nil && ctx.Err() != context.DeadlineExceeded {
+			// the caller left, which tells nothing about the upstream API
+			return Check{Status: StatusDegraded, Detail: publicMessage(err)}
+		}
+
+		h.probed, h.probeErr = time.Now(), err
+	}
+
+	checkedAt := h.probed
+	check := Check{Status: StatusOK, CheckedAt: &checkedAt}
+
+	if h.probeErr != nil {
+		check.Status = StatusDegraded
+		check.Detail = publicMessage(h.probeErr)
+	}
+
+	return check
+}
+
+// check tells whether upstream requests can currently be made without waiting for a back-off
+// to end or being refused by a full queue. It's the upstreamLimiter readiness check, which
+// stands in for the state of a circuit breaker.
+func (l *upstreamLimiter) check() Check {
+	if paused := l.paused(); paused > 0 {
+		return Check{Status: StatusDegraded, Detail: fmt.Sprintf("the upstream API asked to back off for %v", paused.Round(time.Second))}
+	}
+
+	if queued := l.queueDepth(); l.policy == LimitQueue && int64(queued) >= l.maxQueue {
+		return Check{Status: StatusDegraded, Detail: fmt.Sprintf("the upstream queue is full with %d requests", queued)}
+	}
+
+	return Check{Status: StatusOK}
+}
@@ -0,0 +1,241 @@
+package shortdescription_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+func readiness(t *testing.T, descriptor shortdescription.Describer) (int, shortdescription.Readiness) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	shortdescription.NewHandler(descriptor).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var r shortdescription.Readiness
+
+	if err := json.Unmarshal(w.Body.Bytes(), &r); err != nil {
+		t.Fatalf("the body is not JSON: %v", err)
+	}
+
+	return w.Code, r
+}
+
+func TestReadiness(t *testing.T) {
+	testCases := []struct {
+		name           string
+		upstream       mockHttpClient
+		probePerson    string
+		expectedCode   int
+		expectedStatus string
+		expectedChecks map[string]string
+		expectedDetail string // of the probe, if any
+	}{
+		{
+			name:           "no probe",
+			upstream:       mockHttpClient{code: http.StatusInternalServerError},
+			expectedCode:   http.StatusOK,
+			expectedStatus: shortdescription.StatusOK,
+			expectedChecks: map[string]string{"upstreamLimiter": shortdescription.StatusOK},
+		},
+		{
+			name:           "probe found",
+			probePerson:    testPerson,
+			expectedCode:   http.StatusOK,
+			expectedStatus: shortdescription.StatusOK,
+			expectedChecks: map[string]string{
+				"upstreamLimiter": shortdescription.StatusOK,
+				"upstreamProbe":   shortdescription.StatusOK,
+			},
+		},
+		{
+			name:           "probe not found",
+			probePerson:    "Jane Doe",
+			expectedCode:   http.StatusOK,
+			expectedStatus: shortdescription.StatusOK,
+			expectedChecks: map[string]string{
+				"upstreamLimiter": shortdescription.StatusOK,
+				"upstreamProbe":   shortdescription.StatusOK,
+			},
+		},
+		{
+			// every replica would fail it at once
+			name:           "probe failing",
+			upstream:       mockHttpClient{code: http.StatusInternalServerError},
+			probePerson:    testPerson,
+			expectedCode:   http.StatusOK,
+			expectedStatus: shortdescription.StatusDegraded,
+			expectedChecks: map[string]string{
+				"upstreamLimiter": shortdescription.StatusOK,
+				"upstreamProbe":   shortdescription.StatusDegraded,
+			},
+			// the probe is public, so the internal error is not shown
+			expectedDetail: "Wikipedia could not be reached",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			descriptor, err := shortdescription.New(shortdescription.Config{
+				ContactInfo: testContactInfo,
+				HttpClient:  tc.upstream,
+				ProbePerson: tc.probePerson,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			code, r := readiness(t, descriptor)
+			if code != tc.expectedCode {
+				t.Errorf("wanted %v, got %v: %+v", tc.expectedCode, code, r)
+			}
+
+			if r.Status != tc.expectedStatus {
+				t.Errorf("wanted the status to be %q, got %q", tc.expectedStatus, r.Status)
+			}
+
+			checks := map[string]string{}
+			for name, check := range r.Checks {
+				checks[name] = check.Status
+			}
+
+			if len(checks) != len(tc.expectedChecks) {
+				t.Fatalf("wanted checks %v, got %v", tc.expectedChecks, checks)
+			}
+
+			for name, status := range tc.expectedChecks {
+				if checks[name] != status {
+					t.Errorf("wanted %s to be %q, got %+v", name, status, r.Checks[name])
+				}
+			}
+
+			if detail := r.Checks["upstreamProbe"].Detail; detail != tc.expectedDetail {
+				t.Errorf("wanted the probe detail to be %q, got %q", tc.expectedDetail, detail)
+			}
+		})
+	}
+}
+
+func TestReadinessProbeIsCached(t *testing.T) {
+	var calls int32
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}),
+		ProbePerson: testPerson,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, r := readiness(t, descriptor); r.Checks["upstreamProbe"].Status != shortdescription.StatusDegraded {
+			t.Errorf("wanted the probe to be degraded, got %+v", r)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("wanted the probe to be made once, got %d upstream calls", calls)
+	}
+}
+
+// blockedUpstream holds every request back until release is closed.
+type blockedUpstream struct {
+	release  chan struct{}
+	upstream shortdescription.HttpDoer
+}
+
+func (u blockedUpstream) Do(req *http.Request) (*http.Response, error) {
+	<-u.release
+	return u.upstream.Do(req)
+}
+
+func TestReadinessWarmUp(t *testing.T) {
+	upstream := &editableUpstream{description: testDescription}
+	release := make(chan struct{})
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  blockedUpstream{release: release, upstream: upstream},
+		WarmUp:      []string{testNonCanonicalPerson, "Jane Doe"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code, r := readiness(t, descriptor); code != http.StatusServiceUnavailable || r.Checks["warmUp"].Status != shortdescription.StatusUnavailable {
+		t.Errorf("wanted to be unavailable while warming up, got %v: %+v", code, r)
+	}
+
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+
+	for {
+		code, r := readiness(t, descriptor)
+		if code == http.StatusOK {
+			if detail := r.Checks["warmUp"].Detail; detail != "2 of 2 persons looked up" {
+				t.Errorf("wanted the warm-up to be complete, got %q", detail)
+			}
+
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("the warm-up did not complete: %+v", r)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the persons are cached, so the upstream is not asked again
+	upstream.edit("edited")
+
+	descr, err := descriptor.ShortDescription(context.Background(), testPerson, testUserAgent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if descr.Description != testDescription {
+		t.Errorf("wanted the warmed up description, got %q", descr.Description)
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.json")
+	writeClients(t, path, `[]`)
+
+	registry, err := shortdescription.NewFileClientRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo:   testContactInfo,
+		HttpClient:    mockHttpClient{code: http.StatusInternalServerError},
+		Clients:       registry,
+		RequireAPIKey: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/healthz", "/v1/health"} {
+		w := httptest.NewRecorder()
+		shortdescription.NewHandler(descriptor).ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: wanted %v without an API key, got %v: %s", path, http.StatusOK, w.Code, w.Body)
+		}
+	}
+}
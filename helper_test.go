@@ -17,7 +17,7 @@ type testClient struct {
 }
 
 func startTestServer(t *testing.T, d shortdescription.Describer) testClient {
-	server := httptest.NewServer(d)
+	server := httptest.NewServer(shortdescription.NewHandler(d))
 
 	t.Cleanup(func() {
 		server.Close()
@@ -61,6 +61,7 @@ func responseError(r *http.Response) error {
 type mockHttpClient struct {
 	body wikiJSON
 	code int
+	err  error // such as when Wikipedia cannot be reached
 }
 
 const (
@@ -75,6 +76,10 @@ const (
 )
 
 func (m mockHttpClient) Do(req *http.Request) (*http.Response, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
 	w := httptest.NewRecorder()
 
 	if m.code > 0 {
@@ -104,3 +109,19 @@ func (m mockHttpClient) Do(req *http.Request) (*http.Response, error) {
 	w.WriteHeader(http.StatusNotFound)
 	return w.Result(), nil
 }
+
+// handlerClient serves upstream requests through an http.HandlerFunc so that tests can
+// inspect the queries being made.
+type handlerClient http.HandlerFunc
+
+func (h handlerClient) Do(req *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	h(w, req)
+
+	// like a real client, report requests that could not complete in time
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	return w.Result(), nil
+}
@@ -0,0 +1,124 @@
+package shortdescription
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// freshness tells how fresh a ShortDescription is, for the HTTP caching headers.
+type freshness struct {
+	fetched  time.Time // when the short description was fetched from upstream
+	expires  time.Time // when the first of the cache entries it was built from expires
+	revision int64     // ID of the page revision it was read from, 0 if unknown
+}
+
+// cachedUntil takes into account a cache entry the ShortDescription was built from, which
+// expires at expiry.
+func (f *freshness) cachedUntil(expiry time.Time) {
+	if f.expires.IsZero() || expiry.Before(f.expires) {
+		f.expires = expiry
+	}
+}
+
+// writeCacheable answers with descr in the format negotiated with the client, along with the
+// metadata that lets browsers and CDNs cache it for as long as the Describer does, and answers
+// conditional requests with 304 Not Modified. It's cached for as long as the first of the
+// cache entries it was built from, such as the description or the optional fields, lasts.
+//
+// The ETag is built from the title, the description and the revision of the page, so it
+// changes with every edit of the page even if the description stays the same. The rest of
+// the response, which holds the other fields in the negotiated format, is hashed along with
+// them. Last-Modified is when the description was fetched from upstream.
+func (d Describer) writeCacheable(w http.ResponseWriter, req *http.Request, descr ShortDescription, f format, fresh freshness) {
+	body, contentType, err := render(descr, f)
+	if err != nil {
+		d.writeError(w, req, fmt.Errorf("%w: cannot encode the response: %v", ErrInternal, err))
+		return
+	}
+
+	etag := entityTag(descr, fresh.revision, body)
+
+	header := w.Header()
+	vary(header, "Accept")
+	header.Set("ETag", etag)
+	header.Set("Last-Modified", fresh.fetched.UTC().Format(http.TimeFormat))
+	header.Set("Cache-Control", d.cacheControl(fresh.expires))
+
+	if notModified(req, etag, fresh.fetched) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", contentType)
+	_, _ = w.Write(body)
+}
+
+// entityTag builds a strong ETag out of the title, the description and the revision of the
+// page, along with the body of the response.
+func entityTag(descr ShortDescription, revision int64, body []byte) string {
+	h := sha256.New()
+
+	// the parts are length-prefixed so that they cannot run into each other
+	for _, part := range []string{descr.Person, descr.Description, strconv.FormatInt(revision, 10), string(body)} {
+		_, _ = fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+
+	return `"` + base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// cacheControl lets the response be cached until expires, when the first of the cache entries
+// it was built from expires.
+func (d Describer) cacheControl(expires time.Time) string {
+	maxAge := int(time.Until(expires).Seconds())
+	if maxAge <= 0 {
+		return "no-cache"
+	}
+
+	// shared caches cannot tell whether the next client has a valid API key
+	scope := "public"
+	if d.requireAPIKey {
+		scope = "private"
+	}
+
+	return fmt.Sprintf("%s, max-age=%d", scope, maxAge)
+}
+
+// vary adds field to the Vary header, unless it's already there.
+func vary(header http.Header, field string) {
+	for _, value := range header.Values("Vary") {
+		for _, f := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(f), field) {
+				return
+			}
+		}
+	}
+
+	header.Add("Vary", field)
+}
+
+// notModified evaluates the conditional headers of req, as in RFC 9110 section 13.2.2.
+// If-Modified-Since is ignored when If-None-Match is present.
+func notModified(req *http.Request, etag string, modified time.Time) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !modified.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,269 @@
+package shortdescription_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+func TestHTTPCaching(t *testing.T) {
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  &mockHttpClient{body: testContent},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := shortdescription.NewHandler(descriptor)
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/descriptions/"+url.PathEscape(testPerson), nil)
+		req.Header = header
+		req.Header.Set("User-Agent", testUserAgent)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		return w
+	}
+
+	first := get(http.Header{})
+	if first.Code != http.StatusOK {
+		t.Fatalf("wanted %v, got %v: %s", http.StatusOK, first.Code, first.Body)
+	}
+
+	etag := first.Header().Get("ETag")
+	lastModified := first.Header().Get("Last-Modified")
+
+	if !strings.HasPrefix(etag, `"`) || lastModified == "" {
+		t.Fatalf("wanted an ETag and Last-Modified, got %q and %q", etag, lastModified)
+	}
+
+	if cacheControl := first.Header().Get("Cache-Control"); cacheControl != "public, max-age=3599" &&
+		cacheControl != "public, max-age=3600" {
+		t.Errorf("wanted the remaining hour of the cached description, got %q", cacheControl)
+	}
+
+	testCases := []struct {
+		name         string
+		header       http.Header
+		expectedCode int
+	}{
+		{
+			name:         "matching ETag",
+			header:       http.Header{"If-None-Match": {`"other", ` + etag}},
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:         "weak comparison",
+			header:       http.Header{"If-None-Match": {"W/" + etag}},
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:         "other ETag",
+			header:       http.Header{"If-None-Match": {`"other"`}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "not modified since",
+			header:       http.Header{"If-Modified-Since": {lastModified}},
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:         "modified since",
+			header:       http.Header{"If-Modified-Since": {time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "If-None-Match takes precedence",
+			header: http.Header{
+				"If-None-Match":     {`"other"`},
+				"If-Modified-Since": {lastModified},
+			},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := get(tc.header)
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("wanted %v, got %v: %s", tc.expectedCode, w.Code, w.Body)
+			}
+
+			if w.Header().Get("ETag") != etag {
+				t.Errorf("wanted the same ETag %s, got %s", etag, w.Header().Get("ETag"))
+			}
+
+			if w.Code == http.StatusNotModified && w.Body.Len() > 0 {
+				t.Errorf("wanted no body, got %s", w.Body)
+			}
+
+			if w.Code == http.StatusOK && w.Body.String() != first.Body.String() {
+				t.Errorf("wanted %s, got %s", first.Body, w.Body)
+			}
+		})
+	}
+}
+
+func TestHTTPCachingScope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.json")
+	writeClients(t, path, testClients)
+
+	registry, err := shortdescription.NewFileClientRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		cfg      shortdescription.Config
+		expected string
+	}{
+		{
+			name:     "not cached",
+			cfg:      shortdescription.Config{CachedTTL: -1},
+			expected: "no-cache",
+		},
+		{
+			name:     "API key required",
+			cfg:      shortdescription.Config{CachedTTL: time.Minute, Clients: registry, RequireAPIKey: true},
+			expected: "private, max-age=",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cfg.ContactInfo = testContactInfo
+			tc.cfg.HttpClient = &mockHttpClient{body: testContent}
+
+			descriptor, err := shortdescription.New(tc.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/?person="+url.QueryEscape(testPerson), nil)
+			req.Header.Set("User-Agent", testUserAgent)
+			req.Header.Set("X-API-Key", "secret")
+
+			w := httptest.NewRecorder()
+			shortdescription.NewHandler(descriptor).ServeHTTP(w, req)
+
+			if cacheControl := w.Header().Get("Cache-Control"); !strings.HasPrefix(cacheControl, tc.expected) {
+				t.Errorf("wanted %q, got %q", tc.expected, cacheControl)
+			}
+		})
+	}
+}
+
+func TestHTTPCachingRevision(t *testing.T) {
+	revision := 1
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			_, _ = fmt.Fprintf(w, `{"query":{"pages":[{"title":%q,"revisions":[{"revid":%d,"parentid":1,`+
+				`"slots":{"main":{"content":"{{Short description|%s}}"}}}]}]}}`, testPerson, revision, testDescription)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := shortdescription.NewHandler(descriptor)
+
+	etag := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/v1/descriptions/"+url.PathEscape(testPerson), nil)
+		req.Header.Set("User-Agent", testUserAgent)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("wanted %v, got %v: %s", http.StatusOK, w.Code, w.Body)
+		}
+
+		return w.Header().Get("ETag")
+	}
+
+	first := etag()
+
+	descriptor.Forget(testPerson)
+
+	if again := etag(); again != first {
+		t.Errorf("wanted the same ETag for the same revision, got %s and then %s", first, again)
+	}
+
+	// an edit that leaves the description as it was
+	revision = 2
+
+	descriptor.Forget(testPerson)
+
+	if edited := etag(); edited == first {
+		t.Errorf("wanted the ETag to change with the revision, got %s again", edited)
+	}
+}
+
+func TestHTTPCachingDetailsTTL(t *testing.T) {
+	var props []string
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  detailsUpstream(&props),
+		CachedTTL:   time.Hour,
+		DetailsTTL:  time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := shortdescription.NewHandler(descriptor)
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:     "description only",
+			expected: []string{"public, max-age=3599", "public, max-age=3600"},
+		},
+		{
+			name:     "with fields cached for less time",
+			query:    "?fields=url",
+			expected: []string{"public, max-age=59", "public, max-age=60"},
+		},
+		{
+			name:     "with cached fields",
+			query:    "?fields=url",
+			expected: []string{"public, max-age=59", "public, max-age=60"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/descriptions/"+url.PathEscape(testPerson)+tc.query, nil)
+			req.Header.Set("User-Agent", testUserAgent)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("wanted %v, got %v: %s", http.StatusOK, w.Code, w.Body)
+			}
+
+			if cacheControl := w.Header().Get("Cache-Control"); cacheControl != tc.expected[0] && cacheControl != tc.expected[1] {
+				t.Errorf("wanted %q, got %q", tc.expected[1], cacheControl)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package shortdescription
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Infobox holds the facts found in the infobox of a person's page, as plain text.
+// Dates are formatted as YYYY-MM-DD, YYYY-MM or YYYY, depending on how precise they are, or
+// kept as plain text when they cannot be parsed, such as "c. 1500".
+type Infobox struct {
+	Type         string   `json:"type" xml:"type,attr"` // such as "person" for {{Infobox person}}
+	Name         string   `json:"name,omitempty" xml:"name,omitempty"`
+	Occupation   []string `json:"occupation,omitempty" xml:"occupation,omitempty"`
+	Nationality  string   `json:"nationality,omitempty" xml:"nationality,omitempty"`
+	BirthDate    string   `json:"birthDate,omitempty" xml:"birthDate,omitempty"`
+	BirthPlace   string   `json:"birthPlace,omitempty" xml:"birthPlace,omitempty"`
+	DeathDate    string   `json:"deathDate,omitempty" xml:"deathDate,omitempty"`
+	DeathPlace   string   `json:"deathPlace,omitempty" xml:"deathPlace,omitempty"`
+	NotableWorks []string `json:"notableWorks,omitempty" xml:"notableWork,omitempty"`
+
+	// Other holds the rest of the parameters as they are written, keyed by name.
+	Other InfoboxParams `json:"other,omitempty" xml:"other,omitempty"`
+}
+
+// InfoboxParams are infobox parameters keyed by name.
+type InfoboxParams map[string]string
+
+// MarshalXML writes the parameters sorted by name, as <param name="...">value</param>.
+func (p InfoboxParams) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if len(p) == 0 {
+		return nil
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, name := range p.names() {
+		param := xml.StartElement{
+			Name: xml.Name{Local: "param"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: name}},
+		}
+
+		if err := e.EncodeElement(p[name], param); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+func (p InfoboxParams) names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// personInfoboxes are the infoboxes about people whose parameters are understood.
+var personInfoboxes = map[string]bool{
+	"person": true, "scientist": true, "academic": true, "philosopher": true, "economist": true,
+	"engineer": true, "architect": true, "astronaut": true, "medical person": true,
+	"artist": true, "writer": true, "musical artist": true, "actor": true, "comedian": true,
+	"journalist": true, "model": true, "chef": true, "youtube personality": true,
+	"officeholder": true, "politician": true, "royalty": true, "military person": true,
+	"religious biography": true, "saint": true, "criminal": true,
+	"sportsperson": true, "athlete": true, "football biography": true, "basketball biography": true,
+	"baseball biography": true, "ice hockey player": true, "tennis biography": true, "cricketer": true,
+}
+
+// infoboxSearchLimit is how far past the short description the infobox is looked for while
+// streaming, in runes. Infoboxes conventionally come right after it.
+const infoboxSearchLimit = 64 << 10
+
+// findInfobox scans wikitext for the first infobox. It returns nil if there's none or if it's
+// not about a person.
+func findInfobox(r io.RuneScanner) (*Infobox, error) {
+	infobox, err := scanInfobox(r)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errTemplateTooLong) ||
+		errors.Is(err, errScanLimit) {
+		return nil, nil
+	}
+
+	return infobox, err
+}
+
+// scanInfobox is findInfobox, but fails with io.EOF when the wikitext has no infobox, and
+// with the error that stopped the scan when it couldn't tell.
+func scanInfobox(r io.RuneScanner) (*Infobox, error) {
+	name, body, err := findTemplate(r, isInfobox)
+	if err != nil {
+		return nil, err
+	}
+
+	infoboxType := strings.TrimPrefix(normalizeTemplateName(name), "infobox ")
+	if !personInfoboxes[infoboxType] {
+		return nil, nil
+	}
+
+	return newInfobox(infoboxType, splitArgs(body)[1:]), nil
+}
+
+func isInfobox(name string, delim rune) bool {
+	return delim != ':' && strings.HasPrefix(normalizeTemplateName(name), "infobox ")
+}
+
+func newInfobox(infoboxType string, args []string) *Infobox {
+	infobox := Infobox{Type: infoboxType}
+
+	for _, arg := range args {
+		name, value, ok := namedArg(arg)
+		if !ok {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		switch strings.ToLower(strings.ReplaceAll(name, " ", "_")) {
+		case "name":
+			infobox.Name = plainText(value)
+		case "occupation", "occupations", "profession":
+			infobox.Occupation = infoboxList(value, true)
+		case "nationality":
+			infobox.Nationality = plainText(value)
+		case "citizenship":
+			if infobox.Nationality == "" {
+				infobox.Nationality = plainText(value)
+			}
+		case "birth_date":
+			infobox.BirthDate = infoboxDate(value)
+		case "birth_place":
+			infobox.BirthPlace = plainText(value)
+		case "death_date":
+			infobox.DeathDate = infoboxDate(value)
+		case "death_place":
+			infobox.DeathPlace = plainText(value)
+		case "notable_works", "notable_work", "notableworks", "works":
+			infobox.NotableWorks = infoboxList(value, false)
+		default:
+			if infobox.Other == nil {
+				infobox.Other = InfoboxParams{}
+			}
+
+			infobox.Other[name] = value
+		}
+	}
+
+	return &infobox
+}
+
+// listTemplates are the templates used to write lists inside infoboxes.
+var listTemplates = map[string]bool{
+	"hlist": true, "flatlist": true, "flat list": true, "plainlist": true, "plain list": true,
+	"ubl": true, "ublist": true, "unbulleted list": true, "bulleted list": true, "cslist": true,
+	"indented plainlist": true,
+}
+
+var lineBreak = regexp.MustCompile(`(?i)\n|<br\s*/?>`)
+
+// infoboxList splits a list written with list templates, bullets or line breaks into its
+// items as plain text. Comma-separated items are split too if splitCommas is set.
+func infoboxList(value string, splitCommas bool) []string {
+	raw := []string{stripComments(value)}
+
+	if trimmed := strings.TrimSpace(raw[0]); strings.HasPrefix(trimmed, "{{") &&
+		closing(trimmed, 0, "{{", "}}") == len(trimmed)-2 {
+		args := splitArgs(trimmed[2 : len(trimmed)-2])
+
+		if listTemplates[normalizeTemplateName(args[0])] {
+			raw = raw[:0]
+
+			for _, arg := range args[1:] {
+				if _, _, named := namedArg(arg); !named {
+					raw = append(raw, arg)
+				}
+			}
+		}
+	}
+
+	var items []string
+
+	for _, item := range raw {
+		for _, line := range lineBreak.Split(item, -1) {
+			if text := plainText(strings.TrimLeft(strings.TrimSpace(line), "*#")); text != "" {
+				items = append(items, text)
+			}
+		}
+	}
+
+	if len(items) == 1 && splitCommas {
+		items = strings.Split(items[0], ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+	}
+
+	return items
+}
+
+func stripComments(s string) string {
+	for {
+		start := strings.Index(s, "<!--")
+		if start < 0 {
+			return s
+		}
+
+		end := strings.Index(s[start:], "-->")
+		if end < 0 {
+			return s[:start]
+		}
+
+		s = s[:start] + s[start+end+len("-->"):]
+	}
+}
+
+// infoboxDate renders an infobox date as YYYY-MM-DD, YYYY-MM or YYYY when it's given by one
+// of the date templates or written in one of the usual formats, or as plain text otherwise.
+func infoboxDate(value string) string {
+	start := strings.Index(value, "{{")
+	if start < 0 {
+		return freeDate(plainText(value))
+	}
+
+	end := closing(value, start, "{{", "}}")
+	if end < 0 {
+		return freeDate(plainText(value))
+	}
+
+	args := splitArgs(value[start+2 : end])
+	name := normalizeTemplateName(args[0])
+	positional := positionalArgs(args[1:])
+
+	switch name {
+	case "birth date", "birth date and age", "bda", "death date", "death date and age", "dda",
+		"start date", "start date and age", "end date", "birth date and given age",
+		"birth year and age", "birth year", "death year and age", "death year":
+		return isoDate(positional)
+	case "birth-date", "birth-date and age", "death-date", "death-date and age":
+		if len(positional) > 0 {
+			return freeDate(plainText(positional[0]))
+		}
+	}
+
+	return plainText(value)
+}
+
+// freeDateLayouts are the ways dates are usually written by hand, along with the format they
+// are rendered with, which keeps their precision.
+var freeDateLayouts = []struct {
+	layout, format string
+}{
+	{"January 2, 2006", "2006-01-02"},
+	{"January 2 2006", "2006-01-02"},
+	{"2 January 2006", "2006-01-02"},
+	{"Jan 2, 2006", "2006-01-02"},
+	{"2 Jan 2006", "2006-01-02"},
+	{"2006-01-02", "2006-01-02"},
+	{"January 2006", "2006-01"},
+	{"Jan 2006", "2006-01"},
+	{"2006-01", "2006-01"},
+	{"2006", "2006"},
+}
+
+// freeDate renders a date written by hand, such as "May 5, 1950", as YYYY-MM-DD, YYYY-MM or
+// YYYY. Dates it cannot parse, such as "c. 1500", are returned as they are.
+func freeDate(s string) string {
+	s = strings.TrimSpace(s)
+
+	for _, l := range freeDateLayouts {
+		if t, err := time.Parse(l.layout, s); err == nil {
+			return t.Format(l.format)
+		}
+	}
+
+	return s
+}
+
+// isoDate formats the year, month and day arguments of a date template.
+func isoDate(positional []string) string {
+	var parts []int
+
+	for _, p := range positional {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || len(parts) == 3 {
+			break
+		}
+
+		parts = append(parts, n)
+	}
+
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("%04d", parts[0])
+	case 2:
+		return fmt.Sprintf("%04d-%02d", parts[0], parts[1])
+	default:
+		return fmt.Sprintf("%04d-%02d-%02d", parts[0], parts[1], parts[2])
+	}
+}
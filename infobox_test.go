@@ -0,0 +1,138 @@
+package shortdescription_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+var infoboxFixtures = []struct {
+	name     string
+	content  wikiJSON
+	expected *shortdescription.Infobox
+}{
+	{
+		name:     "no infobox",
+		content:  "{{Short description|Country in Western Europe}}",
+		expected: nil,
+	},
+	{
+		name:     "infoboxes not about people are ignored",
+		content:  "{{Short description|Country in Western Europe}}{{Infobox country\n| capital = [[Paris]]}}",
+		expected: nil,
+	},
+	{
+		name: "infobox person",
+		content: "{{Short description|English writer}}\n{{Use British English|date=May 2020}}\n" +
+			"{{Infobox person\n| name = Jane Austen\n| image = Jane Austen.jpg\n" +
+			"| birth_date = {{birth date|1775|12|16|df=y}}\n| birth_place = [[Steventon]], [[Hampshire]], England\n" +
+			"| death_date = {{death date and age|1817|7|18|1775|12|16|df=y}}\n" +
+			"| occupation = Novelist\n| nationality = English\n" +
+			"| notable_works = {{plainlist|\n* ''[[Pride and Prejudice]]''\n* ''[[Emma (novel)|Emma]]''}}\n}}",
+		expected: &shortdescription.Infobox{
+			Type:         "person",
+			Name:         "Jane Austen",
+			Occupation:   []string{"Novelist"},
+			Nationality:  "English",
+			BirthDate:    "1775-12-16",
+			BirthPlace:   "Steventon, Hampshire, England",
+			DeathDate:    "1817-07-18",
+			NotableWorks: []string{"Pride and Prejudice", "Emma"},
+			Other:        map[string]string{"image": "Jane Austen.jpg"},
+		},
+	},
+	{
+		name: "infobox scientist with citizenship and lists",
+		content: "{{Short description|Canadian computer scientist}}\n" +
+			"{{Infobox scientist\n|name = Yoshua Bengio\n|citizenship = Canada<!-- not France -->\n" +
+			"|occupation = {{hlist|[[Computer scientist]]|Professor}}\n|birth_date = {{birth date and age|1964|3|5}}\n" +
+			"|fields = [[Computer science]]\n|doctoral_advisor = Renato de Mori\n}}",
+		expected: &shortdescription.Infobox{
+			Type:        "scientist",
+			Name:        "Yoshua Bengio",
+			Occupation:  []string{"Computer scientist", "Professor"},
+			Nationality: "Canada",
+			BirthDate:   "1964-03-05",
+			Other: map[string]string{
+				"fields":           "[[Computer science]]",
+				"doctoral_advisor": "Renato de Mori",
+			},
+		},
+	},
+	{
+		name: "comma and line break separated lists",
+		content: "{{Short description|American singer}}{{Infobox musical artist\n| name = Someone\n" +
+			"| occupation = Singer, songwriter<br />actress\n| birth_date = 1970\n}}",
+		expected: &shortdescription.Infobox{
+			Type:       "musical artist",
+			Name:       "Someone",
+			Occupation: []string{"Singer, songwriter", "actress"},
+			BirthDate:  "1970",
+		},
+	},
+	{
+		name: "dates written by hand",
+		content: "{{Short description|American actress}}{{Infobox actor\n| name = Someone\n" +
+			"| birth_date = {{birth-date and age|May 5, 1950}}\n| death_date = c. 2020\n" +
+			"| birth_place = Boston\n| years_active = 1970\n}}",
+		expected: &shortdescription.Infobox{
+			Type:       "actor",
+			Name:       "Someone",
+			BirthDate:  "1950-05-05",
+			BirthPlace: "Boston",
+			DeathDate:  "c. 2020",
+			Other:      map[string]string{"years_active": "1970"},
+		},
+	},
+	{
+		name: "month precision dates written by hand",
+		content: "{{Short description|English poet}}{{Infobox writer\n| name = Someone\n" +
+			"| birth_date = {{birth-date|March 1800}}\n| death_date = 4 July 1850\n}}",
+		expected: &shortdescription.Infobox{
+			Type:      "writer",
+			Name:      "Someone",
+			BirthDate: "1800-03",
+			DeathDate: "1850-07-04",
+		},
+	},
+	{
+		name:    "commented out infobox is ignored",
+		content: "{{Short description|English writer}}<!-- {{Infobox person|name=Old}} -->{{Infobox writer\n|name = New}}",
+		expected: &shortdescription.Infobox{
+			Type: "writer",
+			Name: "New",
+		},
+	},
+}
+
+func TestInfoboxFixtures(t *testing.T) {
+	ctx := context.Background()
+	var mockClient mockHttpClient
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  &mockClient,
+		CachedTTL:   -1, // remove caching
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range infoboxFixtures {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient.body = tc.content
+
+			descr, err := descriptor.ShortDescription(ctx, testPerson, testUserAgent,
+				shortdescription.WithFields(shortdescription.FieldInfobox))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(descr.Infobox, tc.expected) {
+				t.Errorf("wanted %+v, got %+v", tc.expected, descr.Infobox)
+			}
+		})
+	}
+}
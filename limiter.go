@@ -0,0 +1,166 @@
+package shortdescription
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LimitPolicy tells what to do with upstream requests once the rate limit is reached.
+type LimitPolicy string
+
+const (
+	// LimitQueue makes requests wait for their turn, as long as the queue is not full.
+	LimitQueue LimitPolicy = "queue"
+	// LimitShed fails requests right away with ErrUnavailable.
+	LimitShed LimitPolicy = "shed"
+)
+
+const (
+	// defaultBackoff is used when the API asks to back off without telling for how long.
+	defaultBackoff = 5 * time.Second
+	// maxBackoffAttempts is how many times a request is sent while the API keeps asking to back off.
+	maxBackoffAttempts = 3
+)
+
+// upstreamLimiter is a token bucket that paces the requests made to the Wikimedia APIs.
+// It can also be paused when the APIs ask to back off.
+type upstreamLimiter struct {
+	rate     float64 // tokens per second, 0 means no limit
+	burst    float64
+	policy   LimitPolicy
+	maxQueue int64
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+
+	queued *gauge // requests waiting for their turn
+}
+
+func newUpstreamLimiter(cfg Config) *upstreamLimiter {
+	return &upstreamLimiter{
+		rate:     cfg.UpstreamRate,
+		burst:    float64(cfg.UpstreamBurst),
+		policy:   cfg.UpstreamPolicy,
+		maxQueue: int64(cfg.UpstreamQueue),
+		tokens:   float64(cfg.UpstreamBurst),
+		last:     time.Now(),
+		queued:   newGauge(cfg.Metrics.SetUpstreamQueueDepth),
+	}
+}
+
+// wait takes a token, waiting for it according to the policy.
+func (l *upstreamLimiter) wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	if l.policy == LimitShed {
+		l.cancel()
+		return fmt.Errorf("%w: upstream rate limit reached", ErrUnavailable)
+	}
+
+	queued := l.queued.add(1)
+	defer l.queued.add(-1)
+
+	if int64(queued) > l.maxQueue {
+		l.cancel()
+		return fmt.Errorf("%w: upstream queue is full", ErrUnavailable)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token, even if it's not available yet, and returns how long to wait for it.
+func (l *upstreamLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	var delay time.Duration
+
+	if l.rate > 0 {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+
+		l.tokens--
+		if l.tokens < 0 {
+			delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+		}
+	}
+
+	l.last = now
+
+	if paused := l.pausedUntil.Sub(now); paused > delay {
+		delay = paused
+	}
+
+	return delay
+}
+
+// cancel gives back a token that won't be used.
+func (l *upstreamLimiter) cancel() {
+	if l.rate == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	l.tokens++
+	l.mu.Unlock()
+}
+
+// paused returns how long requests are still held back for, since the APIs asked to back off.
+func (l *upstreamLimiter) paused() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return time.Until(l.pausedUntil)
+}
+
+// pause holds every request back for d.
+func (l *upstreamLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+func (l *upstreamLimiter) queueDepth() int {
+	return l.queued.value()
+}
+
+// backoffSignal tells whether the API asked to back off, either because it's lagged (see
+// https://www.mediawiki.org/wiki/Manual:Maxlag_parameter) or because it's rate limiting us,
+// and for how long.
+func backoffSignal(res *http.Response) (time.Duration, bool) {
+	if res.StatusCode != http.StatusTooManyRequests && res.Header.Get("MediaWiki-API-Error") != "maxlag" {
+		return 0, false
+	}
+
+	seconds, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return defaultBackoff, true
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
@@ -0,0 +1,155 @@
+package shortdescription_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+func TestMaxLag(t *testing.T) {
+	var calls int
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			calls++
+
+			if req.URL.Query().Get("maxlag") != "5" {
+				t.Errorf("wanted maxlag=5, got %q", req.URL.RawQuery)
+			}
+
+			if calls == 1 {
+				w.Header().Set("MediaWiki-API-Error", "maxlag")
+				w.Header().Set("Retry-After", "1")
+				_, _ = w.Write([]byte(`{"error":{"code":"maxlag","info":"Waiting for a database server: 6 seconds lagged."}}`))
+				return
+			}
+
+			_, _ = w.Write([]byte("{{Short description|" + testDescription + "}}"))
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+
+	descr, err := descriptor.ShortDescription(context.Background(), testPerson, testUserAgent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if descr.Description != testDescription {
+		t.Errorf("wanted %q, got %q", testDescription, descr.Description)
+	}
+
+	if calls != 2 {
+		t.Errorf("wanted the request to be sent again once, got %d calls", calls)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("wanted to back off for a second, retried after %v", elapsed)
+	}
+}
+
+func TestUpstreamLimiter(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policy      shortdescription.LimitPolicy
+		timeout     time.Duration
+		expectedErr error
+	}{
+		{
+			name:        "shed",
+			policy:      shortdescription.LimitShed,
+			expectedErr: shortdescription.ErrUnavailable,
+		},
+		{
+			name:        "queue until the context is done",
+			policy:      shortdescription.LimitQueue,
+			timeout:     50 * time.Millisecond,
+			expectedErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			descriptor, err := shortdescription.New(shortdescription.Config{
+				ContactInfo:    testContactInfo,
+				HttpClient:     &mockHttpClient{body: "{{Short description|" + testDescription + "}}"},
+				CachedTTL:      -1, // remove caching
+				UpstreamRate:   0.01,
+				UpstreamPolicy: tc.policy,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+
+			// the only token in the bucket
+			if _, err := descriptor.ShortDescription(ctx, testPerson, testUserAgent); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			_, err = descriptor.ShortDescription(ctx, testPerson, testUserAgent)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("wanted %v, got %v", tc.expectedErr, err)
+			}
+
+			if depth := descriptor.UpstreamQueueDepth(); depth != 0 {
+				t.Errorf("wanted an empty queue, got %d", depth)
+			}
+		})
+	}
+}
+
+func TestNewUpstreamPolicy(t *testing.T) {
+	_, err := shortdescription.New(shortdescription.Config{
+		ContactInfo:    testContactInfo,
+		UpstreamPolicy: "drop",
+	})
+	if err == nil {
+		t.Error("wanted an error for an unknown upstream policy")
+	}
+}
+
+func TestMaxLagRetryAfter(t *testing.T) {
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("MediaWiki-API-Error", "maxlag")
+			w.Header().Set("Retry-After", "30")
+			_, _ = w.Write([]byte(`{"error":{"code":"maxlag","info":"Waiting for a database server: 6 seconds lagged."}}`))
+		}),
+		UpstreamPolicy: shortdescription.LimitShed,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/descriptions/Yoshua_Bengio", nil)
+	req.Header.Set("User-Agent", testUserAgent)
+
+	w := httptest.NewRecorder()
+	shortdescription.NewHandler(descriptor).ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("wanted %v, got %v: %s", http.StatusServiceUnavailable, w.Code, w.Body)
+	}
+
+	if retryAfter := w.Header().Get("Retry-After"); retryAfter != "30" {
+		t.Errorf("wanted Retry-After 30, got %q", retryAfter)
+	}
+}
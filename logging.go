@@ -0,0 +1,242 @@
+package shortdescription
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessLogger receives an entry for each request served by a Handler. See Config.AccessLogger.
+type AccessLogger interface {
+	LogRequest(entry AccessLogEntry)
+}
+
+// AccessLogEntry describes how a request was served.
+type AccessLogEntry struct {
+	RequestID        string // see RequestIDFromContext
+	Method           string
+	Path             string
+	Person           string // the normalized title, for lookups of a single person
+	Status           int
+	Latency          time.Duration
+	Cache            CacheOutcome // of the lookup of Person, if any
+	UpstreamAttempts int          // requests made to the Wikimedia APIs
+	UserAgent        string
+	Error            string // the internal error, which may be hidden from the client
+}
+
+const (
+	requestIDHeader = "X-Request-ID"
+	maxRequestIDLen = 128
+)
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID makes the lookups done with ctx send id to the Wikimedia APIs in the
+// X-Request-ID header. Handlers do it for every request.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID of ctx, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDContextKey{}).(string)
+	return id, ok
+}
+
+// requestID returns the X-Request-ID sent by the client, or a new one if it sent none that
+// is safe to log and send upstream.
+func requestID(req *http.Request) string {
+	if id := req.Header.Get(requestIDHeader); validRequestID(id) {
+		return id
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// requestStats gathers what happened while serving a request, for the access log.
+type requestStats struct {
+	mu       sync.Mutex
+	person   string
+	cache    CacheOutcome
+	attempts int
+	err      error
+}
+
+type requestStatsContextKey struct{}
+
+func contextWithRequestStats(ctx context.Context, stats *requestStats) context.Context {
+	return context.WithValue(ctx, requestStatsContextKey{}, stats)
+}
+
+// recordRequest updates the stats of the request ctx belongs to, if any.
+func recordRequest(ctx context.Context, record func(stats *requestStats)) {
+	stats, ok := ctx.Value(requestStatsContextKey{}).(*requestStats)
+	if !ok {
+		return
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	record(stats)
+}
+
+// LogLevel is the severity of a log entry.
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+func (l LogLevel) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+
+	return logLevels[l]
+}
+
+// ParseLogLevel parses "debug", "info", "warn" or "error".
+func ParseLogLevel(s string) (LogLevel, error) {
+	for i, name := range logLevels {
+		if strings.EqualFold(s, name) {
+			return LogLevel(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+// JSONLogger writes log entries as JSON objects, one per line, leaving out those below its
+// level. It is an AccessLogger that logs requests at LevelInfo, or at LevelError when they
+// fail with a 5xx status, keeping only a sample of the former.
+type JSONLogger struct {
+	level  LogLevel
+	sample float64
+
+	mu   sync.Mutex
+	enc  *json.Encoder
+	rand *mathrand.Rand
+}
+
+// NewJSONLogger logs to w the entries at level or above. Only a sample fraction of the
+// requests that don't fail with a 5xx status is logged, all of them when sample is 0 or 1.
+func NewJSONLogger(w io.Writer, level LogLevel, sample float64) (*JSONLogger, error) {
+	if sample < 0 || sample > 1 {
+		return nil, fmt.Errorf("the sample must be between 0 and 1, got %v", sample)
+	}
+
+	if sample == 0 {
+		sample = 1
+	}
+
+	return &JSONLogger{
+		level:  level,
+		sample: sample,
+		enc:    json.NewEncoder(w),
+		rand:   mathrand.New(mathrand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// Log writes an entry with msg and fields, if level is high enough.
+func (l *JSONLogger) Log(level LogLevel, msg string, fields map[string]any) {
+	if level < l.level {
+		return
+	}
+
+	entry := make(map[string]any, len(fields)+3)
+	for k, v := range fields {
+		entry[k] = v
+	}
+
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = msg
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_ = l.enc.Encode(entry)
+}
+
+func (l *JSONLogger) LogRequest(e AccessLogEntry) {
+	level := LevelInfo
+	if e.Status >= http.StatusInternalServerError {
+		level = LevelError
+	}
+
+	if level < l.level || (level == LevelInfo && !l.sampled()) {
+		return
+	}
+
+	fields := map[string]any{
+		"requestId": e.RequestID,
+		"method":    e.Method,
+		"path":      e.Path,
+		"status":    e.Status,
+		"latencyMs": float64(e.Latency.Microseconds()) / 1000,
+		"userAgent": e.UserAgent,
+	}
+
+	if e.Person != "" {
+		fields["person"] = e.Person
+	}
+
+	if e.Cache != "" {
+		fields["cache"] = e.Cache
+	}
+
+	if e.UpstreamAttempts > 0 {
+		fields["upstreamAttempts"] = e.UpstreamAttempts
+	}
+
+	if e.Error != "" {
+		fields["error"] = e.Error
+	}
+
+	l.Log(level, "request", fields)
+}
+
+func (l *JSONLogger) sampled() bool {
+	if l.sample >= 1 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rand.Float64() < l.sample
+}
@@ -0,0 +1,157 @@
+package shortdescription_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+func TestAccessLog(t *testing.T) {
+	var logs bytes.Buffer
+
+	logger, err := shortdescription.NewJSONLogger(&logs, shortdescription.LevelInfo, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var upstreamRequestID string
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			upstreamRequestID = req.Header.Get("X-Request-ID")
+			_, _ = w.Write([]byte(testContent))
+		}),
+		AccessLogger: logger,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := shortdescription.NewHandler(descriptor)
+
+	testCases := []struct {
+		name      string
+		requestID string
+		expected  map[string]any
+	}{
+		{
+			name:      "looked up",
+			requestID: "test-request-id",
+			expected: map[string]any{
+				"level": "info", "msg": "request", "requestId": "test-request-id", "method": "GET",
+				"path": "/v1/descriptions/yoshua_Bengio", "person": testPerson, "status": float64(200),
+				"cache": "miss", "upstreamAttempts": float64(1), "userAgent": testUserAgent,
+			},
+		},
+		{
+			name:      "cached",
+			requestID: "test-request-id",
+			expected: map[string]any{
+				"level": "info", "msg": "request", "requestId": "test-request-id", "method": "GET",
+				"path": "/v1/descriptions/yoshua_Bengio", "person": testPerson, "status": float64(200),
+				"cache": "hit", "userAgent": testUserAgent,
+			},
+		},
+		{
+			name:      "unsafe request ID",
+			requestID: "injected\nline",
+			expected: map[string]any{
+				"level": "info", "msg": "request", "method": "GET",
+				"path": "/v1/descriptions/yoshua_Bengio", "person": testPerson, "status": float64(200),
+				"cache": "hit", "userAgent": testUserAgent,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logs.Reset()
+			upstreamRequestID = ""
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/descriptions/yoshua_Bengio", nil)
+			req.Header.Set("User-Agent", testUserAgent)
+			req.Header.Set("X-Request-ID", tc.requestID)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			var entry map[string]any
+
+			if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+				t.Fatalf("the log is not a JSON entry: %v: %s", err, logs.Bytes())
+			}
+
+			requestID := w.Header().Get("X-Request-ID")
+			if requestID == "" || entry["requestId"] != requestID {
+				t.Errorf("wanted the request ID %q to be answered and logged, got %v", requestID, entry["requestId"])
+			}
+
+			if tc.expected["requestId"] == nil && requestID == tc.requestID {
+				t.Errorf("wanted a new request ID instead of %q", tc.requestID)
+			}
+
+			if upstreamRequestID != "" && upstreamRequestID != requestID {
+				t.Errorf("wanted the request ID %q to be sent upstream, got %q", requestID, upstreamRequestID)
+			}
+
+			for key, value := range tc.expected {
+				if entry[key] != value {
+					t.Errorf("wanted %s to be %v, got %v", key, value, entry[key])
+				}
+			}
+
+			for _, key := range []string{"time", "latencyMs"} {
+				if _, ok := entry[key]; !ok {
+					t.Errorf("wanted a %s in %v", key, entry)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONLoggerLevels(t *testing.T) {
+	var logs bytes.Buffer
+
+	// none of the successful requests is sampled
+	logger, err := shortdescription.NewJSONLogger(&logs, shortdescription.LevelInfo, 1e-9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Log(shortdescription.LevelDebug, "too low", nil)
+	logger.Log(shortdescription.LevelWarn, "kept", map[string]any{"key": "value"})
+	logger.LogRequest(shortdescription.AccessLogEntry{Status: http.StatusOK})
+	logger.LogRequest(shortdescription.AccessLogEntry{Status: http.StatusBadGateway, Error: "upstream error"})
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wanted 2 entries, got %q", lines)
+	}
+
+	for i, expected := range []map[string]any{
+		{"level": "warn", "msg": "kept", "key": "value"},
+		{"level": "error", "msg": "request", "status": float64(502), "error": "upstream error"},
+	} {
+		var entry map[string]any
+
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatal(err)
+		}
+
+		for key, value := range expected {
+			if entry[key] != value {
+				t.Errorf("entry %d: wanted %s to be %v, got %v", i, key, value, entry[key])
+			}
+		}
+	}
+
+	if _, err := shortdescription.ParseLogLevel("verbose"); err == nil {
+		t.Error("wanted an error for an unknown level")
+	}
+}
@@ -0,0 +1,112 @@
+package shortdescription
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics receives the measurements taken by a Describer, so that they can be exported to
+// any monitoring system. See Config.Metrics, and the prommetrics package for Prometheus.
+//
+// Implementations can embed NoMetrics to only take some of the measurements.
+type Metrics interface {
+	// ObserveRequest is called after each request served by a Handler, with its route, such
+	// as "/v1/descriptions/{person}" or "other" for unknown paths, the status code it was
+	// answered with and how long it took. Streams are observed once they end.
+	ObserveRequest(route string, status int, duration time.Duration)
+
+	// ObserveCacheLookup is called on every lookup of a cache, with its name and outcome.
+	// See CacheOutcome.
+	ObserveCacheLookup(cache string, outcome CacheOutcome)
+
+	// ObserveCacheEviction is called whenever an entry of a cache is evicted to make room for
+	// another one.
+	ObserveCacheEviction(cache string)
+
+	// SetCacheSize is called with the amount of entries of a cache whenever it changes.
+	SetCacheSize(cache string, n int)
+
+	// ObserveUpstreamRequest is called after each request made to the Wikimedia APIs, with
+	// the API that was called, "action" or "rest", the class of its outcome and how long it
+	// took to answer. The class is "ok", "network" when no answer came, "backoff" when the
+	// API asked to back off, or the error code of the answer, such as "not_found".
+	ObserveUpstreamRequest(api, class string, duration time.Duration)
+
+	// SetUpstreamInFlight is called with the amount of requests made to the Wikimedia APIs
+	// that are waiting for an answer whenever it changes.
+	SetUpstreamInFlight(n int)
+
+	// SetCoalescedLookups is called with the amount of lookups waiting for an identical one
+	// made at the same time, instead of making their own upstream requests, whenever it
+	// changes.
+	SetCoalescedLookups(n int)
+
+	// ObserveBytesRead is called after each wikitext response is read, with the amount of
+	// bytes that were read from it.
+	ObserveBytesRead(n int64)
+
+	// SetUpstreamQueueDepth is called with the amount of requests waiting for the upstream
+	// rate limiter whenever it changes.
+	SetUpstreamQueueDepth(n int)
+}
+
+// CacheOutcome tells how a cache lookup went.
+type CacheOutcome string
+
+const (
+	CacheHit     CacheOutcome = "hit"
+	CacheMiss    CacheOutcome = "miss"
+	CacheExpired CacheOutcome = "expired" // found, but too old to be used
+)
+
+// Names of the caches, as given to Metrics.
+const (
+	CacheDescriptions = "descriptions"
+	CacheFallback     = "fallback" // Wikidata descriptions
+	CacheDetails      = "details"  // optional fields
+	CacheSuggest      = "suggest"  // titles by prefix
+)
+
+// gauge keeps an amount that goes up and down, such as the requests in flight, and reports
+// it with set whenever it changes. It's reported under a lock, so that the last value a
+// Metrics is given is always the current one.
+type gauge struct {
+	mu  sync.Mutex
+	n   int
+	set func(n int)
+}
+
+func newGauge(set func(n int)) *gauge {
+	return &gauge{set: set}
+}
+
+// add changes the amount by delta, and returns the new amount.
+func (g *gauge) add(delta int) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.n += delta
+	g.set(g.n)
+
+	return g.n
+}
+
+func (g *gauge) value() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.n
+}
+
+// NoMetrics discards all measurements.
+type NoMetrics struct{}
+
+func (NoMetrics) ObserveRequest(string, int, time.Duration)            {}
+func (NoMetrics) ObserveCacheLookup(string, CacheOutcome)              {}
+func (NoMetrics) ObserveCacheEviction(string)                          {}
+func (NoMetrics) SetCacheSize(string, int)                             {}
+func (NoMetrics) ObserveUpstreamRequest(string, string, time.Duration) {}
+func (NoMetrics) SetUpstreamInFlight(int)                              {}
+func (NoMetrics) SetCoalescedLookups(int)                              {}
+func (NoMetrics) ObserveBytesRead(int64)                               {}
+func (NoMetrics) SetUpstreamQueueDepth(int)                            {}
@@ -0,0 +1,285 @@
+package shortdescription_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+// recordedMetrics counts the measurements it is given, by kind and labels.
+type recordedMetrics struct {
+	shortdescription.NoMetrics
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *recordedMetrics) record(measurement string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counts == nil {
+		m.counts = map[string]int{}
+	}
+
+	m.counts[measurement]++
+}
+
+func (m *recordedMetrics) ObserveRequest(route string, status int, _ time.Duration) {
+	m.record("request " + route + " " + http.StatusText(status))
+}
+
+func (m *recordedMetrics) ObserveCacheLookup(cache string, outcome shortdescription.CacheOutcome) {
+	m.record("cache " + cache + " " + string(outcome))
+}
+
+func (m *recordedMetrics) ObserveUpstreamRequest(api, class string, _ time.Duration) {
+	m.record("upstream " + api + " " + class)
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := &recordedMetrics{}
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  mockHttpClient{},
+		CachedTTL:   50 * time.Millisecond,
+		Metrics:     metrics,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := shortdescription.NewHandler(descriptor)
+
+	serve := func(target string) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("User-Agent", testUserAgent)
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("/v1/descriptions/Yoshua_Bengio")
+	serve("/v1/descriptions/Yoshua_Bengio")
+	serve("/v1/descriptions/Jane_Doe")
+	serve("/v1/nowhere")
+
+	time.Sleep(50 * time.Millisecond)
+	serve("/v1/descriptions/Yoshua_Bengio")
+
+	expected := map[string]int{
+		"request /v1/descriptions/{person} OK":        3,
+		"request /v1/descriptions/{person} Not Found": 1,
+		"request other Not Found":                     1,
+		"cache descriptions miss":                     2,
+		"cache descriptions hit":                      1,
+		"cache descriptions expired":                  1,
+		"upstream action ok":                          2,
+		"upstream action not_found":                   1,
+	}
+
+	if !reflect.DeepEqual(metrics.counts, expected) {
+		t.Errorf("wanted %v, got %v", expected, metrics.counts)
+	}
+}
+
+// inFlightMetrics keeps the last and the highest amount of upstream requests in flight.
+type inFlightMetrics struct {
+	shortdescription.NoMetrics
+
+	mu        sync.Mutex
+	last, max int
+}
+
+func (m *inFlightMetrics) SetUpstreamInFlight(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.last = n
+	if n > m.max {
+		m.max = n
+	}
+}
+
+func TestUpstreamInFlight(t *testing.T) {
+	metrics := &inFlightMetrics{}
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			time.Sleep(time.Millisecond)
+			_, _ = w.Write([]byte(testContent))
+		}),
+		CachedTTL: -1, // remove caching
+		Metrics:   metrics,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+
+	// different persons, as identical lookups are coalesced
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+			_, _ = descriptor.ShortDescription(context.Background(), fmt.Sprintf("Person %d", i), testUserAgent)
+		}(i)
+	}
+
+	wg.Wait()
+
+	if metrics.last != 0 || metrics.max < 2 {
+		t.Errorf("wanted the requests to be in flight at once and then none, got up to %d and then %d", metrics.max, metrics.last)
+	}
+}
+
+// coalescedMetrics reports the amount of lookups waiting for another one on changed.
+type coalescedMetrics struct {
+	shortdescription.NoMetrics
+	changed chan int
+}
+
+func (m coalescedMetrics) SetCoalescedLookups(n int) {
+	m.changed <- n
+}
+
+func TestCoalescedLookups(t *testing.T) {
+	const lookups = 10
+
+	metrics := coalescedMetrics{changed: make(chan int)}
+
+	var calls int32
+
+	release := make(chan struct{})
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			_, _ = w.Write([]byte(testContent))
+		}),
+		Metrics: metrics,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+
+	descrs := make([]shortdescription.ShortDescription, lookups)
+
+	for i := range descrs {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+			descrs[i], _ = descriptor.ShortDescription(context.Background(), testPerson, testUserAgent)
+		}(i)
+	}
+
+	// every lookup but the first waits for it
+	for n := range metrics.changed {
+		if n == lookups-1 {
+			break
+		}
+	}
+
+	close(release)
+
+	go func() {
+		wg.Wait()
+		close(metrics.changed)
+	}()
+
+	for n := range metrics.changed {
+		if n < 0 {
+			t.Errorf("wanted the waiting lookups to never be negative, got %d", n)
+		}
+	}
+
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("wanted a single upstream request, got %d", calls)
+	}
+
+	for i, descr := range descrs {
+		if descr.Description != testDescription {
+			t.Errorf("lookup %d: wanted %s, got %+v", i, testDescription, descr)
+		}
+	}
+}
+
+func TestCoalescedLookupCancelled(t *testing.T) {
+	metrics := coalescedMetrics{changed: make(chan int)}
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			started <- struct{}{}
+
+			select {
+			case <-release:
+				_, _ = w.Write([]byte(testContent))
+			case <-req.Context().Done():
+			}
+		}),
+		Metrics: metrics,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+
+	go func() {
+		_, err := descriptor.ShortDescription(ctx, testPerson, testUserAgent)
+		first <- err
+	}()
+
+	<-started
+
+	second := make(chan shortdescription.ShortDescription)
+
+	go func() {
+		descr, _ := descriptor.ShortDescription(context.Background(), testPerson, testUserAgent)
+		second <- descr
+	}()
+
+	if n := <-metrics.changed; n != 1 {
+		t.Fatalf("wanted the second lookup to wait for the first one, got %d waiting", n)
+	}
+
+	go func() {
+		for range metrics.changed {
+		}
+	}()
+
+	cancel()
+
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted the first lookup to be cancelled, got %v", err)
+	}
+
+	close(release)
+
+	// the second lookup does not share the cancellation, and looks the person up itself
+	if descr := <-second; descr.Description != testDescription {
+		t.Errorf("wanted %s, got %+v", testDescription, descr)
+	}
+}
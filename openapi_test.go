@@ -0,0 +1,287 @@
+package shortdescription_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+type openAPISpec struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Parameters map[string]openAPIParameter `json:"parameters"`
+		Responses  map[string]openAPIResponse  `json:"responses"`
+		Schemas    map[string]openAPISchema    `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	Parameters []openAPIParameter         `json:"parameters"`
+	Responses  map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Ref      string `json:"$ref"`
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+	Example  any    `json:"example"`
+}
+
+type openAPIResponse struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema openAPISchema `json:"schema"`
+	} `json:"content"`
+}
+
+type openAPISchema struct {
+	Ref        string                   `json:"$ref"`
+	Required   []string                 `json:"required"`
+	Properties map[string]openAPISchema `json:"properties"`
+	Enum       []string                 `json:"enum"`
+}
+
+func loadOpenAPI(t *testing.T) openAPISpec {
+	t.Helper()
+
+	var spec openAPISpec
+
+	if err := json.Unmarshal(shortdescription.OpenAPI, &spec); err != nil {
+		t.Fatal("the OpenAPI document is not valid JSON:", err)
+	}
+
+	return spec
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// TestOpenAPISchemas checks that the documented schemas match the JSON of the Go types.
+func TestOpenAPISchemas(t *testing.T) {
+	spec := loadOpenAPI(t)
+
+	types := map[string]any{
+		"ShortDescription": shortdescription.ShortDescription{},
+		"Infobox":          shortdescription.Infobox{},
+		"Batch":            shortdescription.Batch{},
+		"BulkResult":       shortdescription.BulkResult{},
+		"Suggestions":      shortdescription.Suggestions{},
+		"Readiness":        shortdescription.Readiness{},
+		"Check":            shortdescription.Check{},
+		"ErrorResponse":    shortdescription.ErrorResponse{},
+		"ProblemDetails":   shortdescription.ProblemDetails{},
+	}
+
+	for name, v := range types {
+		schema, ok := spec.Components.Schemas[name]
+		if !ok {
+			t.Errorf("%s is not documented", name)
+			continue
+		}
+
+		properties, required := jsonProperties(reflect.TypeOf(v))
+
+		var documented []string
+		for property := range schema.Properties {
+			documented = append(documented, property)
+		}
+
+		sort.Strings(properties)
+		sort.Strings(required)
+		sort.Strings(documented)
+		sort.Strings(schema.Required)
+
+		if !reflect.DeepEqual(properties, documented) {
+			t.Errorf("%s: wanted properties %v, documented %v", name, properties, documented)
+		}
+
+		if len(required) > 0 && !reflect.DeepEqual(required, schema.Required) {
+			t.Errorf("%s: wanted required properties %v, documented %v", name, required, schema.Required)
+		}
+	}
+}
+
+// jsonProperties lists the JSON properties of a struct, including those of its embedded
+// structs, and which of them are always present.
+func jsonProperties(typ reflect.Type) (properties, required []string) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		if field.Anonymous {
+			p, r := jsonProperties(field.Type)
+			properties, required = append(properties, p...), append(required, r...)
+
+			continue
+		}
+
+		tag, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		properties = append(properties, tag)
+
+		if opts != "omitempty" {
+			required = append(required, tag)
+		}
+	}
+
+	return properties, required
+}
+
+// TestOpenAPIRoutes checks that every route of Handler is documented.
+func TestOpenAPIRoutes(t *testing.T) {
+	spec := loadOpenAPI(t)
+
+	for _, route := range shortdescription.Routes() {
+		if _, ok := spec.Paths[route]; !ok {
+			t.Errorf("%s is not documented", route)
+		}
+	}
+}
+
+// TestOpenAPIOperations calls every documented operation, with and without its required
+// parameters, and checks that the answers are documented.
+func TestOpenAPIOperations(t *testing.T) {
+	spec := loadOpenAPI(t)
+
+	var calls int32
+
+	path := filepath.Join(t.TempDir(), "clients.json")
+	writeClients(t, path, `[{"key": "admin", "name": "Admin", "contactInfo": "admin@example.org", "admin": true}]`)
+
+	registry, err := shortdescription.NewFileClientRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  suggestUpstream(&calls, 0),
+		Clients:     registry,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := shortdescription.NewHandler(descriptor)
+	codes := spec.Components.Schemas["ErrorCode"].Enum
+
+	for path, operations := range spec.Paths {
+		for method, op := range operations {
+			for _, withParams := range []bool{true, false} {
+				target, ok := operationTarget(spec, path, op, withParams)
+				if !ok {
+					continue
+				}
+
+				req := httptest.NewRequest(strings.ToUpper(method), target, nil)
+				req.Header.Set("User-Agent", testUserAgent)
+				req.Header.Set("X-API-Key", "admin")
+
+				if _, streamed := op.Responses["200"].Content["text/event-stream"]; streamed {
+					// event streams go on until the client leaves
+					ctx, cancel := context.WithTimeout(req.Context(), 100*time.Millisecond)
+					defer cancel()
+
+					req = req.WithContext(ctx)
+				}
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+
+				res, documented := op.Responses[strconv.Itoa(w.Code)]
+				if !documented {
+					t.Errorf("%s %s: %v is not documented: %s", method, target, w.Code, w.Body)
+					continue
+				}
+
+				if res.Ref != "" {
+					res = spec.Components.Responses[refName(res.Ref)]
+				}
+
+				content, ok := res.Content["application/json"]
+				if !ok || content.Schema.Ref == "" {
+					continue
+				}
+
+				schema := spec.Components.Schemas[refName(content.Schema.Ref)]
+
+				var body map[string]any
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Errorf("%s %s: the body is not JSON: %v", method, target, err)
+					continue
+				}
+
+				for property := range body {
+					if _, ok := schema.Properties[property]; !ok {
+						t.Errorf("%s %s: %q is not documented", method, target, property)
+					}
+				}
+
+				if code, ok := body["code"].(string); ok && !contains(codes, code) {
+					t.Errorf("%s %s: error code %q is not documented", method, target, code)
+				}
+			}
+		}
+	}
+}
+
+// operationTarget builds the URL of an operation from the examples of its parameters. It
+// returns false when the operation has no required parameters to leave out.
+func operationTarget(spec openAPISpec, path string, op openAPIOperation, withParams bool) (string, bool) {
+	query := url.Values{}
+	left := false
+
+	for _, param := range op.Parameters {
+		if param.Ref != "" {
+			param = spec.Components.Parameters[refName(param.Ref)]
+		}
+
+		if !param.Required || param.In != "query" {
+			if param.In == "path" {
+				path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(param.Example.(string)))
+			}
+
+			continue
+		}
+
+		if !withParams {
+			left = true
+			continue
+		}
+
+		switch example := param.Example.(type) {
+		case []any:
+			for _, e := range example {
+				query.Add(param.Name, e.(string))
+			}
+		default:
+			query.Set(param.Name, example.(string))
+		}
+	}
+
+	if !withParams && !left {
+		return "", false
+	}
+
+	return path + "?" + query.Encode(), true
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
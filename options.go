@@ -0,0 +1,34 @@
+package shortdescription
+
+// Option customizes a single lookup.
+type Option func(*options)
+
+type options struct {
+	wikidataFallback bool
+	fields           []Field
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithWikidataFallback uses the Wikidata description of the pages that explicitly have no
+// short description. ShortDescription.Source is then set to SourceWikidata.
+func WithWikidataFallback() Option {
+	return func(o *options) {
+		o.wikidataFallback = true
+	}
+}
+
+// WithFields adds the given optional fields to the ShortDescription. Only the upstream
+// properties they need are fetched.
+func WithFields(fields ...Field) Option {
+	return func(o *options) {
+		o.fields = append(o.fields, fields...)
+	}
+}
@@ -0,0 +1,149 @@
+// Package prommetrics collects the metrics of a shortdescription.Describer with Prometheus.
+package prommetrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+const namespace = "shortdescription"
+
+// Metrics implements shortdescription.Metrics with Prometheus collectors.
+type Metrics struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	cacheLookups   *prometheus.CounterVec
+	cacheEvictions *prometheus.CounterVec
+	cacheSize      *prometheus.GaugeVec
+
+	upstreamRequests        *prometheus.CounterVec
+	upstreamRequestDuration *prometheus.HistogramVec
+	upstreamInFlight        prometheus.Gauge
+	coalescedLookups        prometheus.Gauge
+	upstreamQueueDepth      prometheus.Gauge
+	bytesRead               prometheus.Histogram
+}
+
+var _ shortdescription.Metrics = Metrics{}
+
+// New registers the collectors with registerer, such as prometheus.DefaultRegisterer, which
+// can then be served with promhttp.
+func New(registerer prometheus.Registerer) (Metrics, error) {
+	m := Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Requests served, by route and status code.",
+		}, []string{"route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "How long requests took to be served, by route. Streams are observed once they end.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route"}),
+
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Cache lookups, by cache and outcome: hit, miss or expired.",
+		}, []string{"cache", "outcome"}),
+		cacheEvictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_evictions_total",
+			Help:      "Cache entries evicted to make room for others, by cache.",
+		}, []string{"cache"}),
+		cacheSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "cache_entries",
+			Help:      "Entries held by each cache, including the expired ones.",
+		}, []string{"cache"}),
+
+		upstreamRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_requests_total",
+			Help:      "Requests made to the Wikimedia APIs, by API and outcome class.",
+		}, []string{"api", "class"}),
+		upstreamRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_request_duration_seconds",
+			Help:      "How long the Wikimedia APIs took to answer, by API.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"api"}),
+		upstreamInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "upstream_requests_in_flight",
+			Help:      "Requests made to the Wikimedia APIs waiting for an answer.",
+		}),
+		coalescedLookups: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "coalesced_lookups_in_flight",
+			Help:      "Lookups waiting for an identical one made at the same time instead of calling the Wikimedia APIs.",
+		}),
+		upstreamQueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "upstream_queue_depth",
+			Help:      "Requests waiting for the upstream rate limiter.",
+		}),
+		bytesRead: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "wikitext_bytes_read",
+			Help:      "Bytes of wikitext read per lookup.",
+			Buckets:   prometheus.ExponentialBuckets(1024, 4, 8), // 1KiB to 16MiB
+		}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		m.requests, m.requestDuration,
+		m.cacheLookups, m.cacheEvictions, m.cacheSize,
+		m.upstreamRequests, m.upstreamRequestDuration, m.upstreamInFlight, m.coalescedLookups, m.upstreamQueueDepth, m.bytesRead,
+	} {
+		if err := registerer.Register(c); err != nil {
+			return Metrics{}, err
+		}
+	}
+
+	return m, nil
+}
+
+func (m Metrics) ObserveRequest(route string, status int, duration time.Duration) {
+	m.requests.WithLabelValues(route, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(route).Observe(duration.Seconds())
+}
+
+func (m Metrics) ObserveCacheLookup(cache string, outcome shortdescription.CacheOutcome) {
+	m.cacheLookups.WithLabelValues(cache, string(outcome)).Inc()
+}
+
+func (m Metrics) ObserveCacheEviction(cache string) {
+	m.cacheEvictions.WithLabelValues(cache).Inc()
+}
+
+func (m Metrics) SetCacheSize(cache string, n int) {
+	m.cacheSize.WithLabelValues(cache).Set(float64(n))
+}
+
+func (m Metrics) ObserveUpstreamRequest(api, class string, duration time.Duration) {
+	m.upstreamRequests.WithLabelValues(api, class).Inc()
+	m.upstreamRequestDuration.WithLabelValues(api).Observe(duration.Seconds())
+}
+
+func (m Metrics) SetUpstreamInFlight(n int) {
+	m.upstreamInFlight.Set(float64(n))
+}
+
+func (m Metrics) SetCoalescedLookups(n int) {
+	m.coalescedLookups.Set(float64(n))
+}
+
+func (m Metrics) ObserveBytesRead(n int64) {
+	m.bytesRead.Observe(float64(n))
+}
+
+func (m Metrics) SetUpstreamQueueDepth(n int) {
+	m.upstreamQueueDepth.Set(float64(n))
+}
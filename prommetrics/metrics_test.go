@@ -0,0 +1,81 @@
+package prommetrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+	"github.com/Inuart/wikimedia-exercise/prommetrics"
+)
+
+// upstream answers every revisions query with a short description.
+type upstream struct{}
+
+func (upstream) Do(req *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	_, err := w.WriteString(`{"query": {"pages": [{"title": "Yoshua Bengio", "revisions": [{"slots": {"main": {"content": "{{Short description|Canadian computer scientist}}"}}}]}]}}`)
+
+	return w.Result(), err
+}
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	metrics, err := prommetrics.New(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: "test contact info",
+		HttpClient:  upstream{},
+		Metrics:     metrics,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := shortdescription.NewHandler(descriptor)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/v1/descriptions/Yoshua_Bengio", nil)
+		req.Header.Set("User-Agent", "test user agent")
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	expected := `
+# HELP shortdescription_cache_lookups_total Cache lookups, by cache and outcome: hit, miss or expired.
+# TYPE shortdescription_cache_lookups_total counter
+shortdescription_cache_lookups_total{cache="descriptions",outcome="hit"} 1
+shortdescription_cache_lookups_total{cache="descriptions",outcome="miss"} 1
+# HELP shortdescription_cache_entries Entries held by each cache, including the expired ones.
+# TYPE shortdescription_cache_entries gauge
+shortdescription_cache_entries{cache="descriptions"} 1
+# HELP shortdescription_requests_total Requests served, by route and status code.
+# TYPE shortdescription_requests_total counter
+shortdescription_requests_total{route="/v1/descriptions/{person}",status="200"} 2
+# HELP shortdescription_upstream_requests_total Requests made to the Wikimedia APIs, by API and outcome class.
+# TYPE shortdescription_upstream_requests_total counter
+shortdescription_upstream_requests_total{api="action",class="ok"} 1
+`
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"shortdescription_cache_lookups_total",
+		"shortdescription_cache_entries",
+		"shortdescription_requests_total",
+		"shortdescription_upstream_requests_total",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if _, err := prommetrics.New(registry); err == nil {
+		t.Error("wanted the collectors to be registered only once")
+	}
+}
@@ -0,0 +1,254 @@
+package shortdescription
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+	apiKeyParam  = "api_key"
+)
+
+// ClientKey identifies the client a request comes from, so that each client gets its own
+// rate limit.
+type ClientKey func(req *http.Request) string
+
+// ClientIP keys clients by the IP address of their connection.
+func ClientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// ClientForwardedFor keys clients by the last address of a forwarded-for header, such as
+// "X-Forwarded-For". Only use it behind a trusted proxy that sets that header, as clients can
+// send anything in it. Requests without the header are keyed by ClientIP.
+func ClientForwardedFor(header string) ClientKey {
+	return func(req *http.Request) string {
+		values := req.Header.Values(header)
+		if len(values) == 0 {
+			return ClientIP(req)
+		}
+
+		addresses := strings.Split(values[len(values)-1], ",")
+		if address := strings.TrimSpace(addresses[len(addresses)-1]); address != "" {
+			return "ip:" + address
+		}
+
+		return ClientIP(req)
+	}
+}
+
+// ClientAPIKey keys clients by the API key sent in the "X-API-Key" header or the "api_key"
+// query parameter, once it's been validated against Config.Clients. Requests without a
+// registered API key are keyed by fallback, so that clients cannot get a new limit by
+// making up keys.
+func ClientAPIKey(fallback ClientKey) ClientKey {
+	return func(req *http.Request) string {
+		if client, ok := clientFromContext(req.Context()); ok {
+			return "key:" + client.Key
+		}
+
+		return fallback(req)
+	}
+}
+
+func apiKey(req *http.Request) string {
+	if key := req.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+
+	return req.URL.Query().Get(apiKeyParam)
+}
+
+// RateLimit is the outcome of taking a token from the bucket of a client.
+type RateLimit struct {
+	Allowed    bool
+	Remaining  int           // tokens left in the bucket
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token, when not allowed
+}
+
+// RateLimitStore keeps the token buckets of the clients. It can be implemented on top of
+// shared storage so that clients are limited across instances.
+type RateLimitStore interface {
+	// Take takes a token from the bucket of key, which holds up to burst tokens and is
+	// refilled at rate tokens per second.
+	Take(ctx context.Context, key string, rate float64, burst int) (RateLimit, error)
+}
+
+type clientBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryRateLimitStore keeps the token buckets of up to size clients in memory, forgetting
+// the least recently seen ones.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets *lru.Cache[string, clientBucket]
+}
+
+func NewMemoryRateLimitStore(size int) (*MemoryRateLimitStore, error) {
+	buckets, err := lru.New[string, clientBucket](size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemoryRateLimitStore{buckets: buckets}, nil
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, rate float64, burst int) (RateLimit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	capacity := float64(burst)
+
+	bucket, ok := s.buckets.Get(key)
+	if !ok {
+		bucket = clientBucket{tokens: capacity}
+	} else {
+		bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	}
+
+	bucket.last = now
+
+	var limit RateLimit
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		limit.Allowed = true
+	} else {
+		limit.RetryAfter = seconds((1 - bucket.tokens) / rate)
+	}
+
+	s.buckets.Add(key, bucket)
+
+	limit.Remaining = int(bucket.tokens)
+	limit.Reset = seconds((capacity - bucket.tokens) / rate)
+
+	return limit, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// clientLimiter limits the requests each client can make to the handler.
+type clientLimiter struct {
+	rate  float64
+	burst int
+	key   ClientKey
+	store RateLimitStore
+}
+
+// quota is the token bucket a client is limited by.
+type quota struct {
+	key   string
+	rate  float64
+	burst int
+}
+
+// quota returns the bucket of the client of req. Registered clients are limited by their API
+// key, with their own quota if they have one.
+func (l *clientLimiter) quota(req *http.Request, client *Client) quota {
+	q := quota{rate: l.rate, burst: l.burst}
+
+	if client == nil {
+		q.key = l.key(req)
+		return q
+	}
+
+	q.key = "key:" + client.Key
+
+	if client.Rate > 0 {
+		q.rate, q.burst = client.Rate, client.Burst
+		if q.burst == 0 {
+			q.burst = int(math.Ceil(q.rate))
+		}
+	}
+
+	return q
+}
+
+func (q quota) exceeded() error {
+	return fmt.Errorf("%w: the limit of %g requests per second was exceeded", ErrRateLimited, q.rate)
+}
+
+// allow takes a token from q and sets the RateLimit-* headers on header, as drafted in
+// https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/. When the client is
+// over its limit, it sets Retry-After and fails with ErrRateLimited.
+func (l *clientLimiter) allow(ctx context.Context, header http.Header, q quota) error {
+	if q.rate == 0 {
+		return nil
+	}
+
+	limit, err := l.store.Take(ctx, q.key, q.rate, q.burst)
+	if err != nil {
+		// a failing store shouldn't take the whole service down with it
+		return nil
+	}
+
+	header.Set("RateLimit-Limit", strconv.Itoa(q.burst))
+	header.Set("RateLimit-Remaining", strconv.Itoa(limit.Remaining))
+	header.Set("RateLimit-Reset", ceilSeconds(limit.Reset))
+
+	if !limit.Allowed {
+		header.Set("Retry-After", ceilSeconds(limit.RetryAfter))
+		return q.exceeded()
+	}
+
+	return nil
+}
+
+// charge takes n more tokens from q, for requests that go on to make as many lookups as n
+// requests would. It fails with ErrRateLimited as soon as the client runs out of them.
+func (l *clientLimiter) charge(ctx context.Context, q quota, n int) error {
+	if q.rate == 0 {
+		return nil
+	}
+
+	for i := 0; i < n; i++ {
+		limit, err := l.store.Take(ctx, q.key, q.rate, q.burst)
+		if err != nil {
+			return nil
+		}
+
+		if !limit.Allowed {
+			return q.exceeded()
+		}
+	}
+
+	return nil
+}
+
+type quotaContextKey struct{}
+
+// charge takes n more tokens from the quota of the client ctx was admitted for, if it's rate
+// limited. See Describer.Admit.
+func (d Describer) charge(ctx context.Context, n int) error {
+	q, ok := ctx.Value(quotaContextKey{}).(quota)
+	if !ok || d.clientLimiter == nil {
+		return nil
+	}
+
+	return d.clientLimiter.charge(ctx, q, n)
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
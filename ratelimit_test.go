@@ -0,0 +1,169 @@
+package shortdescription_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+func TestClientRateLimit(t *testing.T) {
+	testCases := []struct {
+		name      string
+		clientKey shortdescription.ClientKey
+		clients   string // registered, as in the API keys file
+		requests  []*http.Request
+		expected  []int
+	}{
+		{
+			name:      "by IP",
+			clientKey: shortdescription.ClientIP,
+			requests: []*http.Request{
+				limitRequest("10.0.0.1:1234", nil),
+				limitRequest("10.0.0.1:5678", nil),
+				limitRequest("10.0.0.1:1234", nil),
+				limitRequest("10.0.0.2:1234", nil),
+			},
+			expected: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name:      "by trusted forwarded-for header",
+			clientKey: shortdescription.ClientForwardedFor("X-Forwarded-For"),
+			requests: []*http.Request{
+				limitRequest("10.0.0.1:1234", http.Header{"X-Forwarded-For": {"spoofed, 192.0.2.1"}}),
+				limitRequest("10.0.0.1:1234", http.Header{"X-Forwarded-For": {"192.0.2.1"}}),
+				limitRequest("10.0.0.1:1234", http.Header{"X-Forwarded-For": {"192.0.2.1"}}),
+				limitRequest("10.0.0.1:1234", http.Header{"X-Forwarded-For": {"192.0.2.2"}}),
+			},
+			expected: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name:      "by API key",
+			clientKey: shortdescription.ClientAPIKey(shortdescription.ClientIP),
+			clients: `[
+				{"key": "first", "name": "First", "contactInfo": "first@example.org"},
+				{"key": "second", "name": "Second", "contactInfo": "second@example.org"}
+			]`,
+			requests: []*http.Request{
+				limitRequest("10.0.0.1:1234", http.Header{"X-Api-Key": {"first"}}),
+				limitRequest("10.0.0.2:1234", http.Header{"X-Api-Key": {"first"}}),
+				limitRequest("10.0.0.3:1234", http.Header{"X-Api-Key": {"first"}}),
+				limitRequest("10.0.0.1:1234", http.Header{"X-Api-Key": {"second"}}),
+				limitRequest("10.0.0.1:1234", nil),
+			},
+			expected: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:      "by IP when API keys are not registered",
+			clientKey: shortdescription.ClientAPIKey(shortdescription.ClientIP),
+			requests: []*http.Request{
+				limitRequest("10.0.0.1:1234", http.Header{"X-Api-Key": {"made up"}}),
+				limitRequest("10.0.0.1:1234", http.Header{"X-Api-Key": {"made up too"}}),
+				limitRequest("10.0.0.1:1234", http.Header{"X-Api-Key": {"another one"}}),
+			},
+			expected: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var clients shortdescription.ClientRegistry
+
+			if tc.clients != "" {
+				path := filepath.Join(t.TempDir(), "clients.json")
+				writeClients(t, path, tc.clients)
+
+				registry, err := shortdescription.NewFileClientRegistry(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				clients = registry
+			}
+
+			descriptor, err := shortdescription.New(shortdescription.Config{
+				ContactInfo: testContactInfo,
+				HttpClient:  &mockHttpClient{},
+				ClientRate:  0.01,
+				ClientBurst: 2,
+				ClientKey:   tc.clientKey,
+				Clients:     clients,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, req := range tc.requests {
+				w := httptest.NewRecorder()
+				descriptor.ServeHTTP(w, req)
+
+				if w.Code != tc.expected[i] {
+					t.Fatalf("request %d: wanted %v, got %v: %s", i, tc.expected[i], w.Code, w.Body)
+				}
+
+				if w.Header().Get("RateLimit-Limit") != "2" {
+					t.Errorf("request %d: wanted RateLimit-Limit 2, got %q", i, w.Header().Get("RateLimit-Limit"))
+				}
+
+				if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "100" {
+					t.Errorf("request %d: wanted Retry-After 100, got %q", i, w.Header().Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
+
+func limitRequest(remoteAddr string, header http.Header) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/?person="+url.QueryEscape(testPerson), nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("User-Agent", testUserAgent)
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	return req
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	store, err := shortdescription.NewMemoryRateLimitStore(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	expected := []shortdescription.RateLimit{
+		{Allowed: true, Remaining: 1},
+		{Allowed: true, Remaining: 0},
+		{Allowed: false, Remaining: 0},
+	}
+
+	for i, want := range expected {
+		limit, err := store.Take(ctx, "client", 1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if limit.Allowed != want.Allowed || limit.Remaining != want.Remaining {
+			t.Errorf("take %d: wanted %+v, got %+v", i, want, limit)
+		}
+
+		if !limit.Allowed && (limit.RetryAfter <= 0 || limit.Reset <= limit.RetryAfter) {
+			t.Errorf("take %d: wanted to retry before the reset, got %+v", i, limit)
+		}
+	}
+
+	// the least recently seen client is forgotten, and starts over with a full bucket
+	if _, err := store.Take(ctx, "other", 1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if limit, _ := store.Take(ctx, "client", 1, 2); !limit.Allowed || limit.Remaining != 1 {
+		t.Errorf("wanted a full bucket, got %+v", limit)
+	}
+}
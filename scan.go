@@ -0,0 +1,151 @@
+package shortdescription
+
+import (
+	"errors"
+	"io"
+)
+
+// errScanLimit is returned once the configured scan limit is reached.
+var errScanLimit = errors.New("scan limit reached")
+
+// byteCounter counts the bytes read from an upstream response, and stops reading once the
+// limit is reached. A limit of 0 means no limit.
+type byteCounter struct {
+	r     io.Reader
+	read  int64
+	limit int64
+}
+
+func (c *byteCounter) Read(p []byte) (int, error) {
+	if c.limit > 0 {
+		if c.read >= c.limit {
+			return 0, errScanLimit
+		}
+
+		if remaining := c.limit - c.read; int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+
+	n, err := c.r.Read(p)
+	c.read += int64(n)
+
+	return n, err
+}
+
+// lineLimiter stops reading after the given amount of lines. A limit of 0 means no limit.
+type lineLimiter struct {
+	io.RuneScanner
+	lines int
+	limit int
+	last  rune
+}
+
+func (l *lineLimiter) ReadRune() (rune, int, error) {
+	c, size, err := l.RuneScanner.ReadRune()
+	if err != nil {
+		return c, size, err
+	}
+
+	l.last = c
+
+	if c == '\n' {
+		l.lines++
+
+		if l.limit > 0 && l.lines >= l.limit {
+			return 0, 0, errScanLimit
+		}
+	}
+
+	return c, size, nil
+}
+
+func (l *lineLimiter) UnreadRune() error {
+	err := l.RuneScanner.UnreadRune()
+	if err == nil && l.last == '\n' {
+		l.lines--
+	}
+
+	return err
+}
+
+// limitedRuneScanner reads up to n runes, and then fails with errScanLimit.
+type limitedRuneScanner struct {
+	io.RuneScanner
+	n int
+}
+
+func (l *limitedRuneScanner) ReadRune() (rune, int, error) {
+	if l.n <= 0 {
+		return 0, 0, errScanLimit
+	}
+
+	c, size, err := l.RuneScanner.ReadRune()
+	if err == nil {
+		l.n--
+	}
+
+	return c, size, err
+}
+
+func (l *limitedRuneScanner) UnreadRune() error {
+	err := l.RuneScanner.UnreadRune()
+	if err == nil {
+		l.n++
+	}
+
+	return err
+}
+
+// revisionIDKey precedes the ID of the page revision in the responses of the MediaWiki API,
+// which comes before its content when asked for rvprop=ids|content.
+const revisionIDKey = `"revid":`
+
+// revisionReader picks the ID of the page revision out of an API response while it's read.
+type revisionReader struct {
+	io.RuneScanner
+	id      int64
+	matched int  // runes of revisionIDKey read so far, or -1 once the ID was read
+	unread  bool // the next rune was already seen
+}
+
+func (r *revisionReader) ReadRune() (rune, int, error) {
+	c, size, err := r.RuneScanner.ReadRune()
+	if err != nil {
+		return c, size, err
+	}
+
+	if r.unread {
+		r.unread = false
+	} else {
+		r.watch(c)
+	}
+
+	return c, size, nil
+}
+
+func (r *revisionReader) UnreadRune() error {
+	err := r.RuneScanner.UnreadRune()
+	r.unread = err == nil
+
+	return err
+}
+
+func (r *revisionReader) watch(c rune) {
+	switch {
+	case r.matched < 0:
+	case r.matched == len(revisionIDKey):
+		if c >= '0' && c <= '9' {
+			r.id = r.id*10 + int64(c-'0')
+			return
+		}
+
+		r.matched = -1
+	case c == rune(revisionIDKey[r.matched]):
+		r.matched++
+	case c == rune(revisionIDKey[0]):
+		r.matched = 1
+	default:
+		r.matched = 0
+	}
+}
@@ -1,94 +1,118 @@
 package shortdescription
 
 import (
-	"bufio"
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"net/url"
+	"strings"
 )
 
 type ShortDescription struct {
-	Person      string `json:"person"`
-	Description string `json:"description,omitempty"`
+	Person      string `json:"person" xml:"person"`
+	Description string `json:"description,omitempty" xml:"description,omitempty"`
+
+	// None is set when the page explicitly has no short description, as in {{Short description|none}}.
+	None bool `json:"none,omitempty" xml:"none,omitempty"`
+	// Source is set when Description does not come from the page itself, as with
+	// WithWikidataFallback or BackendSummary.
+	Source string `json:"source,omitempty" xml:"source,omitempty"`
+
+	// The following are only set when requested. See WithFields.
+	Extract   string   `json:"extract,omitempty" xml:"extract,omitempty"`
+	Thumbnail string   `json:"thumbnail,omitempty" xml:"thumbnail,omitempty"`
+	BirthDate string   `json:"birthDate,omitempty" xml:"birthDate,omitempty"`
+	DeathDate string   `json:"deathDate,omitempty" xml:"deathDate,omitempty"`
+	URL       string   `json:"url,omitempty" xml:"url,omitempty"`
+	Infobox   *Infobox `json:"infobox,omitempty" xml:"infobox,omitempty"`
+}
+
+// description is what gets cached for each page.
+type description struct {
+	text     string
+	none     bool
+	source   string
+	revision int64 // ID of the page revision it was read from, 0 if unknown
+
+	// infobox is only meaningful if the wikitext was scanned for it, as told by hasInfobox.
+	infobox    *Infobox
+	hasInfobox bool
+}
+
+func (d description) shortDescription(person string) ShortDescription {
+	return ShortDescription{
+		Person:      person,
+		Description: d.text,
+		None:        d.none,
+		Source:      d.source,
+	}
 }
 
 // apiURL prevents urls from accidentally being used without being processed first.
 type apiURL string
 
-const shortDescriptionURL apiURL = "https://en.wikipedia.org/w/api.php?action=query&prop=revisions&rvlimit=1&formatversion=2&format=json&rvprop=content&rvslots=main&titles="
+const shortDescriptionURL apiURL = "https://en.wikipedia.org/w/api.php?action=query&prop=revisions&rvlimit=1&formatversion=2&format=json&rvprop=ids%7Ccontent&rvslots=main&titles="
 
 func getShortDescriptionURL(title string) string {
 	return string(shortDescriptionURL) + url.QueryEscape(title)
 }
 
-func readShortDescription(r io.Reader) (string, error) {
-	descr, err := readBetween(r, "{{Short description|", "}}")
-	if errors.Is(err, io.EOF) {
-		return "", fmt.Errorf("short description %w", ErrNotFound)
+// readShortDescription reads the short description out of wikitext while it's streamed.
+// If withInfobox is set, the infobox is looked for in the same pass, since it's usually right
+// after it. It's only taken as missing if the rest of the page was read without finding it.
+// Otherwise the reading stops right after the short description.
+func readShortDescription(r io.RuneScanner, withInfobox bool) (description, error) {
+	var infoboxBefore bool
+
+	descr, err := toDescription(findShortDescription(r, func(name string, delim rune) {
+		infoboxBefore = infoboxBefore || isInfobox(name, delim)
+	}))
+	if err != nil || !withInfobox || infoboxBefore {
+		return descr, err
 	}
 
-	return descr, err
+	descr.infobox, err = scanInfobox(&limitedRuneScanner{r, infoboxSearchLimit})
+	descr.hasInfobox = err == nil || errors.Is(err, io.EOF)
+
+	return descr, nil
 }
 
-// I couldn't find a short implementation that avoided holding data in memory
-// while searching the io.Reader, so here's a custom one.
-func readBetween(r io.Reader, from, to string) (string, error) {
-	br := bufio.NewReader(r)
-	if err := discardUntil(br, from); err != nil {
-		return "", err
+// parseShortDescription reads the short description and the infobox out of wikitext that is
+// already in memory.
+func parseShortDescription(content string) (description, error) {
+	descr, err := toDescription(findShortDescription(strings.NewReader(content), nil))
+	if err != nil {
+		return description{}, err
 	}
 
-	return readUntil(br, to)
-}
-
-func discardUntil(r *bufio.Reader, until string) error {
-	_, err := rawReadUntil(r, until, true)
-	return err
-}
+	descr.infobox, err = scanInfobox(strings.NewReader(content))
+	descr.hasInfobox = err == nil || errors.Is(err, io.EOF)
 
-func readUntil(r *bufio.Reader, until string) (string, error) {
-	return rawReadUntil(r, until, false)
+	return descr, nil
 }
 
-func rawReadUntil(r *bufio.Reader, until string, discard bool) (string, error) {
-	var buf []byte
-
-	for {
-		chunk, err := r.ReadBytes(until[len(until)-1])
-		if err != nil {
-			return "", fmt.Errorf("rawReadUntil: %w", err)
-		}
-
-		buf = append(buf, chunk...)
-		if bytes.HasSuffix(buf, []byte(until)) {
-			if discard {
-				return "", nil
-			}
-
-			return string(buf[:len(buf)-len(until)]), nil
-		}
-
-		if discard && len(buf) > len(until) {
-			buf = buf[len(buf)-len(until):]
-		}
+func toDescription(text string, err error) (description, error) {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errTemplateTooLong) {
+		return description{}, fmt.Errorf("short description %w", ErrNotFound)
 	}
-}
 
-// I'm leaving a previous implementation here just in case it's valueble
-// for the exercise review. It's shorter but less efficient.
+	if errors.Is(err, errNoArgument) {
+		return description{}, fmt.Errorf("short description is missing: %w", ErrNotFound)
+	}
 
-/*var shortDescrRegexp = regexp.MustCompile(`{{Short description\|(.+?)}}`)
+	if errors.Is(err, errScanLimit) {
+		return description{}, fmt.Errorf("short description %w within the scan limit", ErrNotFound)
+	}
 
-func readShortDescription1(r io.Reader) (string, error) {
-	var buf bytes.Buffer
-	r = io.TeeReader(r, &buf)
+	if err != nil {
+		return description{}, err
+	}
 
-	indexs := shortDescrRegexp.FindReaderSubmatchIndex(bufio.NewReader(r))
-	if len(indexs) < 4 {
-		return "", ErrNotFound
+	// https://en.wikipedia.org/wiki/Template:Short_description#Pages_that_don't_need_a_short_description
+	// An explicitly empty {{Short description|}} says as much as "none".
+	if text == "" || strings.EqualFold(text, "none") {
+		return description{none: true}, nil
 	}
 
-	return buf.String()[indexs[2]:indexs[3]], nil
-}*/
+	return description{text: text}, nil
+}
@@ -0,0 +1,323 @@
+package shortdescription
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// subscriptions keeps track of the persons subscribed to, and refreshes them in the
+// background while there are subscribers.
+type subscriptions struct {
+	refresh    time.Duration
+	timeout    time.Duration // of each refresh
+	maxPersons int
+	max        int
+	lookup     func(titles []string) // looks titles up again, bypassing the cache
+
+	mu      sync.Mutex
+	titles  map[string]*subscribedTitle
+	count   int
+	stop    chan struct{} // stops the refresher, nil when it is not running
+	refetch chan string   // titles to refresh right away, such as those forgotten
+}
+
+type subscribedTitle struct {
+	subscribers map[*subscriber]bool
+	last        ShortDescription
+	known       bool   // whether last was ever set
+	version     uint64 // how many times it was published
+}
+
+type subscriber struct {
+	updates chan ShortDescription
+	sent    map[string]bool // titles already sent, guarded by subscriptions.mu
+}
+
+func newSubscriptions(cfg Config) *subscriptions {
+	return &subscriptions{
+		refresh:    cfg.SubscriptionRefresh,
+		timeout:    cfg.SubscriptionRefreshTimeout,
+		maxPersons: cfg.SubscriptionMaxPersons,
+		max:        cfg.MaxSubscriptions,
+		titles:     map[string]*subscribedTitle{},
+		refetch:    make(chan string, MaxBatchSize),
+	}
+}
+
+// Subscribe sends the short descriptions of up to Config.SubscriptionMaxPersons persons to
+// the returned channel, first as they are and then whenever they change, until ctx is done.
+// A person without a short description, or whose short description was removed, is sent
+// with an empty Description.
+//
+// Subscribed persons are looked up again every Config.SubscriptionRefresh, and right away
+// when they are forgotten, each time for up to Config.SubscriptionRefreshTimeout. Subscribers that fall behind are dropped by closing the channel.
+func (d Describer) Subscribe(ctx context.Context, persons []string, userAgent string) (<-chan ShortDescription, error) {
+	s := d.subscriptions
+
+	if len(persons) == 0 || len(persons) > s.maxPersons {
+		return nil, fmt.Errorf("%w: between 1 and %d persons can be subscribed to at once", ErrInvalidArgument, s.maxPersons)
+	}
+
+	titles := make([]string, 0, len(persons))
+	seen := make(map[string]bool, len(persons))
+
+	for _, person := range persons {
+		if person == "" || strings.Contains(person, "|") {
+			return nil, fmt.Errorf("%w: %q is not a valid person", ErrInvalidArgument, person)
+		}
+
+		title := normalizeTitle(person)
+		if !seen[title] {
+			seen[title] = true
+			titles = append(titles, title)
+		}
+	}
+
+	sub := &subscriber{
+		updates: make(chan ShortDescription, 2*len(titles)),
+		sent:    make(map[string]bool, len(titles)),
+	}
+
+	versions, err := s.add(sub, titles)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.remove(sub, titles)
+	}()
+
+	descrs, err := d.shortDescriptions(ctx, titles, userAgent)
+	if err != nil {
+		s.remove(sub, titles)
+		return nil, err
+	}
+
+	for _, title := range titles {
+		current := ShortDescription{Person: title}
+		if descr, ok := descrs[title]; ok {
+			current = descr.shortDescription(title)
+		}
+
+		s.send(sub, title, current, versions[title])
+	}
+
+	return sub.updates, nil
+}
+
+// add subscribes sub to titles, returning the version each of them had.
+func (s *subscriptions) add(sub *subscriber, titles []string) (map[string]uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count >= s.max {
+		return nil, fmt.Errorf("%w: there are too many subscriptions open, please try again later", ErrRateLimited)
+	}
+
+	s.count++
+
+	versions := make(map[string]uint64, len(titles))
+
+	for _, title := range titles {
+		t, ok := s.titles[title]
+		if !ok {
+			t = &subscribedTitle{subscribers: map[*subscriber]bool{}}
+			s.titles[title] = t
+		}
+
+		t.subscribers[sub] = true
+		versions[title] = t.version
+	}
+
+	if s.stop == nil {
+		s.stop = make(chan struct{})
+		go s.run(s.stop)
+	}
+
+	return versions, nil
+}
+
+// remove unsubscribes sub and closes its channel, if it wasn't already.
+func (s *subscriptions) remove(sub *subscriber, titles []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.drop(sub, titles)
+}
+
+// drop is remove, with s.mu held.
+func (s *subscriptions) drop(sub *subscriber, titles []string) {
+	removed := false
+
+	for _, title := range titles {
+		t, ok := s.titles[title]
+		if !ok || !t.subscribers[sub] {
+			continue
+		}
+
+		removed = true
+
+		delete(t.subscribers, sub)
+
+		if len(t.subscribers) == 0 {
+			delete(s.titles, title)
+		}
+	}
+
+	if !removed {
+		return
+	}
+
+	close(sub.updates)
+	s.count--
+
+	if s.count == 0 && s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// send sends the current short description of a title to a new subscriber, which was looked
+// up when the title had the given version. It's not sent if a newer one already was, and the
+// newer one is sent instead if it was published without being sent, as it didn't change.
+func (s *subscriptions) send(sub *subscriber, title string, current ShortDescription, version uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.titles[title]
+	if !ok || !t.subscribers[sub] || sub.sent[title] {
+		return // already gone or sent
+	}
+
+	if t.version != version {
+		current = t.last
+	} else if !t.known {
+		t.last, t.known = current, true
+	}
+
+	sub.sent[title] = true
+	s.deliver(sub, current)
+}
+
+// publish sends the short description of a title to its subscribers if it changed.
+func (s *subscriptions) publish(title string, current ShortDescription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.titles[title]
+	if !ok {
+		return
+	}
+
+	changed := t.known && t.last != current
+	t.last, t.known = current, true
+	t.version++
+
+	if !changed {
+		return
+	}
+
+	for sub := range t.subscribers {
+		sub.sent[title] = true
+		s.deliver(sub, current)
+	}
+}
+
+// deliver sends an update without waiting, dropping subscribers that fall behind.
+func (s *subscriptions) deliver(sub *subscriber, update ShortDescription) {
+	select {
+	case sub.updates <- update:
+	default:
+		var titles []string
+		for title, t := range s.titles {
+			if t.subscribers[sub] {
+				titles = append(titles, title)
+			}
+		}
+
+		s.drop(sub, titles)
+	}
+}
+
+// subscribed lists the titles with subscribers.
+func (s *subscriptions) subscribed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	titles := make([]string, 0, len(s.titles))
+	for title := range s.titles {
+		titles = append(titles, title)
+	}
+
+	return titles
+}
+
+// invalidate asks for title to be looked up again, if it has subscribers.
+func (s *subscriptions) invalidate(title string) {
+	s.mu.Lock()
+	_, ok := s.titles[title]
+	s.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	select {
+	case s.refetch <- title:
+	default: // it will be refreshed with the rest
+	}
+}
+
+// store caches the description of a title, letting its subscribers know if it changed.
+func (d Describer) store(title string, descr description) time.Time {
+	added := d.cache.Add(title, descr)
+	d.subscriptions.publish(title, descr.shortDescription(title))
+
+	return added
+}
+
+// run looks up the subscribed titles again every refresh interval, and those invalidated
+// right away, until stop is closed once there are no subscribers left.
+func (s *subscriptions) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case title := <-s.refetch:
+			s.lookup([]string{title})
+		case <-ticker.C:
+			s.lookup(s.subscribed())
+		}
+	}
+}
+
+// refreshTitles looks up titles bypassing the cache, and stores what it finds.
+func (d Describer) refreshTitles(titles []string) {
+	if len(titles) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.subscriptions.timeout)
+	defer cancel()
+
+	fetched := make(map[string]description, len(titles))
+
+	err := d.backend.describeBatch(ctx, titles, d.userAgent, fetched)
+
+	for _, title := range titles {
+		if descr, ok := fetched[title]; ok {
+			d.store(title, descr)
+		} else if err == nil {
+			// the short description is gone
+			d.cache.Remove(title)
+			d.subscriptions.publish(title, ShortDescription{Person: title})
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package shortdescription_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+// editableUpstream answers batched revisions queries with a short description for
+// testPerson that can be edited, and no page for anyone else.
+type editableUpstream struct {
+	mu          sync.Mutex
+	description string
+}
+
+func (u *editableUpstream) edit(description string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.description = description
+}
+
+func (u *editableUpstream) Do(req *http.Request) (*http.Response, error) {
+	return handlerClient(func(w http.ResponseWriter, req *http.Request) {
+		u.mu.Lock()
+		content := "{{Short description|" + u.description + "}}"
+		if u.description == "" {
+			content = "" // removed, rather than explicitly empty
+		}
+		u.mu.Unlock()
+
+		var pages []any
+
+		for _, title := range strings.Split(req.URL.Query().Get("titles"), "|") {
+			if title != testPerson {
+				pages = append(pages, map[string]any{"title": title, "missing": true})
+				continue
+			}
+
+			pages = append(pages, map[string]any{
+				"title":     title,
+				"revisions": []any{map[string]any{"slots": map[string]any{"main": map[string]any{"content": content}}}},
+			})
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"query": map[string]any{"pages": pages}})
+	}).Do(req)
+}
+
+func receive(t *testing.T, updates <-chan shortdescription.ShortDescription) shortdescription.ShortDescription {
+	t.Helper()
+
+	select {
+	case update, ok := <-updates:
+		if !ok {
+			t.Fatal("the subscription ended")
+		}
+
+		return update
+	case <-time.After(time.Second):
+		t.Fatal("no update was received")
+	}
+
+	return shortdescription.ShortDescription{}
+}
+
+func TestSubscribe(t *testing.T) {
+	upstream := &editableUpstream{description: testDescription}
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo:         testContactInfo,
+		HttpClient:          upstream,
+		SubscriptionRefresh: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, err := descriptor.Subscribe(ctx, []string{testNonCanonicalPerson, "Jane Doe"}, testUserAgent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []shortdescription.ShortDescription{
+		{Person: testPerson, Description: testDescription},
+		{Person: "Jane Doe"},
+	} {
+		if update := receive(t, updates); update != expected {
+			t.Errorf("wanted %+v first, got %+v", expected, update)
+		}
+	}
+
+	upstream.edit("Canadian-French computer scientist")
+
+	expected := shortdescription.ShortDescription{Person: testPerson, Description: "Canadian-French computer scientist"}
+	if update := receive(t, updates); update != expected {
+		t.Errorf("wanted %+v after the edit, got %+v", expected, update)
+	}
+
+	// the refreshes that change nothing are not sent
+	select {
+	case update := <-updates:
+		t.Errorf("wanted no more updates, got %+v", update)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-updates:
+		if ok {
+			t.Error("wanted the subscription to end with its context")
+		}
+	case <-time.After(time.Second):
+		t.Error("the subscription did not end with its context")
+	}
+}
+
+func TestSubscribeWithoutCaching(t *testing.T) {
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  &editableUpstream{description: testDescription},
+		CachedTTL:   -1, // remove caching
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, err := descriptor.Subscribe(ctx, []string{testPerson}, testUserAgent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := shortdescription.ShortDescription{Person: testPerson, Description: testDescription}
+	if update := receive(t, updates); update != expected {
+		t.Errorf("wanted %+v, got %+v", expected, update)
+	}
+}
+
+func TestSubscribeForget(t *testing.T) {
+	upstream := &editableUpstream{description: testDescription}
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  upstream,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, err := descriptor.Subscribe(ctx, []string{testPerson}, testUserAgent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receive(t, updates)
+
+	// without waiting for the hourly refresh
+	upstream.edit("")
+	descriptor.Forget(testPerson)
+
+	expected := shortdescription.ShortDescription{Person: testPerson}
+	if update := receive(t, updates); update != expected {
+		t.Errorf("wanted the description to be gone, got %+v", update)
+	}
+}
+
+// heldUpstream holds back the requests made for heldUserAgent, telling on held, until release
+// is closed.
+type heldUpstream struct {
+	held     chan struct{}
+	release  chan struct{}
+	upstream shortdescription.HttpDoer
+}
+
+const heldUserAgent = "held/1.0"
+
+func (u heldUpstream) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == heldUserAgent {
+		u.held <- struct{}{}
+		<-u.release
+	}
+
+	return u.upstream.Do(req)
+}
+
+func TestSubscribeChangedWhileLookingUp(t *testing.T) {
+	upstream := &editableUpstream{description: testDescription}
+	held := heldUpstream{held: make(chan struct{}, 1), release: make(chan struct{}), upstream: upstream}
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  held,
+		CachedTTL:   -1, // remove caching, so that every subscription looks the person up
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first, err := descriptor.Subscribe(ctx, []string{testPerson}, testUserAgent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receive(t, first)
+
+	type subscription struct {
+		updates <-chan shortdescription.ShortDescription
+		err     error
+	}
+
+	subscribed := make(chan subscription, 1)
+
+	go func() {
+		updates, err := descriptor.Subscribe(ctx, []string{testPerson}, heldUserAgent)
+		subscribed <- subscription{updates, err}
+	}()
+
+	// the second subscription is looking the person up when it's edited
+	<-held.held
+
+	upstream.edit("edited")
+	descriptor.Forget(testPerson)
+
+	expected := shortdescription.ShortDescription{Person: testPerson, Description: "edited"}
+	if update := receive(t, first); update != expected {
+		t.Fatalf("wanted %+v, got %+v", expected, update)
+	}
+
+	close(held.release)
+
+	second := <-subscribed
+	if second.err != nil {
+		t.Fatal(second.err)
+	}
+
+	if update := receive(t, second.updates); update != expected {
+		t.Errorf("wanted %+v, got %+v", expected, update)
+	}
+
+	// the lookup found what was already sent
+	select {
+	case update := <-second.updates:
+		t.Errorf("wanted no more updates, got %+v", update)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSubscribeHandler(t *testing.T) {
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo:            testContactInfo,
+		HttpClient:             &editableUpstream{description: testDescription},
+		SubscriptionMaxPersons: 2,
+		MaxSubscriptions:       1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := startTestServer(t, descriptor)
+
+	subscribe := func(query string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, client.url+"/v1/subscribe?"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("User-Agent", testUserAgent)
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() { res.Body.Close() })
+
+		return res
+	}
+
+	if res := subscribe("person=a&person=b&person=c"); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("wanted %v for too many persons, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+
+	res := subscribe("person=Yoshua+Bengio")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("wanted %v, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	if contentType := res.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("wanted an event stream, got %q", contentType)
+	}
+
+	events := bufio.NewReader(res.Body)
+
+	var event string
+
+	for {
+		line, err := events.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if line == "\n" {
+			break
+		}
+
+		event += line
+	}
+
+	expected := "event: description\ndata: {\"person\":\"Yoshua Bengio\",\"description\":\"Canadian computer scientist\"}\n"
+	if event != expected {
+		t.Errorf("wanted %q, got %q", expected, event)
+	}
+
+	if res := subscribe("person=Yoshua+Bengio"); res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("wanted %v over the subscriptions limit, got %v", http.StatusTooManyRequests, res.StatusCode)
+	}
+}
@@ -0,0 +1,110 @@
+package shortdescription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// MaxSuggestLimit is the maximum amount of suggestions that can be requested at once.
+const MaxSuggestLimit = maxBatchTitles
+
+type Suggestions struct {
+	Prefix      string             `json:"prefix"`
+	Suggestions []ShortDescription `json:"suggestions"`
+}
+
+const prefixSearchURL apiURL = "https://en.wikipedia.org/w/api.php?action=query&list=prefixsearch&psnamespace=0&formatversion=2&format=json&pslimit=%d&pssearch="
+
+func getPrefixSearchURL(prefix string, limit int) string {
+	return fmt.Sprintf(string(prefixSearchURL), limit) + url.QueryEscape(prefix)
+}
+
+// Suggest returns up to limit titles starting with prefix, each with its short description if
+// it has one. A limit of 0 uses the configured SuggestLimit.
+//
+// Suggest works within the configured SuggestTimeout. If that budget runs out while looking
+// up descriptions, the titles are still returned with the descriptions that were found in time.
+func (d Describer) Suggest(ctx context.Context, prefix, userAgent string, limit int) (Suggestions, error) {
+	if prefix == "" {
+		return Suggestions{}, fmt.Errorf("%w: prefix is empty", ErrInvalidArgument)
+	}
+
+	if userAgent == "" {
+		return Suggestions{}, fmt.Errorf("%w: userAgent is empty", ErrInvalidArgument)
+	}
+
+	if limit == 0 {
+		limit = d.suggestLimit
+	}
+
+	if limit < 0 || limit > MaxSuggestLimit {
+		return Suggestions{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidArgument, MaxSuggestLimit)
+	}
+
+	prefix = normalizeTitle(prefix)
+
+	ctx, cancel := context.WithTimeout(ctx, d.suggestTimeout)
+	defer cancel()
+
+	titles, err := d.prefixSearch(ctx, prefix, limit, userAgent)
+	if err != nil {
+		return Suggestions{}, err
+	}
+
+	descrs, err := d.shortDescriptions(ctx, titles, userAgent)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return Suggestions{}, err
+	}
+
+	suggestions := Suggestions{
+		Prefix:      prefix,
+		Suggestions: make([]ShortDescription, 0, len(titles)),
+	}
+
+	for _, title := range titles {
+		suggestions.Suggestions = append(suggestions.Suggestions, descrs[title].shortDescription(title))
+	}
+
+	return suggestions, nil
+}
+
+type prefixSearchResponse struct {
+	Query struct {
+		PrefixSearch []struct {
+			Title string `json:"title"`
+		} `json:"prefixsearch"`
+	} `json:"query"`
+}
+
+// prefixSearch returns the titles starting with prefix, caching them by prefix and limit.
+func (d Describer) prefixSearch(ctx context.Context, prefix string, limit int, userAgent string) ([]string, error) {
+	key := fmt.Sprintf("%d|%s", limit, prefix)
+
+	if titles, ok := d.suggestCache.Get(key); ok {
+		return titles, nil
+	}
+
+	res, err := d.fetch(ctx, getPrefixSearchURL(prefix, limit), userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	var psr prefixSearchResponse
+	if err := json.NewDecoder(res.Body).Decode(&psr); err != nil {
+		return nil, fmt.Errorf("%w: cannot decode prefix search response: %v", ErrUpstream, err)
+	}
+
+	titles := make([]string, 0, len(psr.Query.PrefixSearch))
+	for _, page := range psr.Query.PrefixSearch {
+		titles = append(titles, page.Title)
+	}
+
+	d.suggestCache.Add(key, titles)
+
+	return titles, nil
+}
@@ -0,0 +1,239 @@
+package shortdescription_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+const testPrefix = "yoshua"
+
+var testSuggestions = map[string]string{
+	"Yoshua Bengio": testDescription,
+	"Yoshua Lo":     "", // a page without short description
+}
+
+// suggestUpstream mimics the prefix search and the batched revisions queries of the MediaWiki API.
+func suggestUpstream(calls *int32, delay time.Duration) handlerClient {
+	return func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(calls, 1)
+
+		query := req.URL.Query()
+
+		if query.Get("list") == "prefixsearch" {
+			if query.Get("pssearch") != "Yoshua" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"query":{"prefixsearch":[{"title":"Yoshua Bengio"},{"title":"Yoshua Lo"}]}}`))
+
+			return
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return
+		}
+
+		type page struct {
+			Title     string `json:"title"`
+			Revisions []any  `json:"revisions"`
+		}
+
+		var pages []page
+
+		for _, title := range strings.Split(query.Get("titles"), "|") {
+			content := "no description here"
+			if descr := testSuggestions[title]; descr != "" {
+				content = "{{Short description|" + descr + "}}"
+			}
+
+			pages = append(pages, page{
+				Title:     title,
+				Revisions: []any{map[string]any{"slots": map[string]any{"main": map[string]any{"content": content}}}},
+			})
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"query": map[string]any{"pages": pages}})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  suggestUpstream(&calls, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name          string
+		prefix        string
+		limit         int
+		expectedCalls int32
+		expectedErr   error
+	}{
+		{
+			name:        "fails if there's no prefix",
+			expectedErr: shortdescription.ErrInvalidArgument,
+		},
+		{
+			name:        "fails if the limit is too big",
+			prefix:      testPrefix,
+			limit:       shortdescription.MaxSuggestLimit + 1,
+			expectedErr: shortdescription.ErrInvalidArgument,
+		},
+		{
+			name:          "happy path searches and then looks up descriptions",
+			prefix:        testPrefix,
+			expectedCalls: 2,
+		},
+		{
+			name:          "titles and descriptions come from the caches",
+			prefix:        testPrefix,
+			expectedCalls: 1, // titles without a description are not cached
+		},
+		{
+			name:          "a different limit searches again but reuses the descriptions",
+			prefix:        testPrefix,
+			limit:         5,
+			expectedCalls: 2, // the title without a description is looked up again
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+
+			suggestions, err := descriptor.Suggest(ctx, tc.prefix, testUserAgent, tc.limit)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("wanted %v, got %v", tc.expectedErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if calls != tc.expectedCalls {
+				t.Errorf("wanted %d upstream calls, got %d", tc.expectedCalls, calls)
+			}
+
+			checkSuggestions(t, suggestions.Suggestions, testSuggestions)
+		})
+	}
+}
+
+func TestSuggestTimeout(t *testing.T) {
+	var calls int32
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo:    testContactInfo,
+		HttpClient:     suggestUpstream(&calls, time.Minute),
+		SuggestTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := descriptor.Suggest(context.Background(), testPrefix, testUserAgent, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkSuggestions(t, suggestions.Suggestions, map[string]string{"Yoshua Bengio": "", "Yoshua Lo": ""})
+}
+
+func TestSuggestHandler(t *testing.T) {
+	var calls int32
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  suggestUpstream(&calls, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := startTestServer(t, descriptor)
+
+	testCases := []struct {
+		name         string
+		query        string
+		expectedCode int
+	}{
+		{
+			name:         "prefix param missing",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "limit is not a number",
+			query:        "?prefix=" + testPrefix + "&limit=ten",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "check result",
+			query:        "?prefix=" + testPrefix,
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := client.Get(client.url + "/suggest" + tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer res.Body.Close()
+
+			if res.StatusCode != tc.expectedCode {
+				t.Fatalf("wanted %v, got %v: %v", tc.expectedCode, res.StatusCode, responseError(res))
+			}
+
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+
+			var result shortdescription.Suggestions
+
+			if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+				t.Fatal("json decoding failed", err)
+			}
+
+			checkSuggestions(t, result.Suggestions, testSuggestions)
+		})
+	}
+}
+
+func checkSuggestions(t *testing.T, got []shortdescription.ShortDescription, expected map[string]string) {
+	t.Helper()
+
+	if len(got) != len(expected) {
+		t.Fatalf("wanted %d suggestions, got %d", len(expected), len(got))
+	}
+
+	for _, s := range got {
+		descr, ok := expected[s.Person]
+		if !ok {
+			t.Errorf("unexpected suggestion %q", s.Person)
+		}
+
+		if s.Description != descr {
+			t.Errorf("wanted %q for %q, got %q", descr, s.Person, s.Description)
+		}
+	}
+}
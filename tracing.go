@@ -0,0 +1,87 @@
+package shortdescription
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans of this package.
+const tracerName = "github.com/Inuart/wikimedia-exercise"
+
+// Attributes of the spans, besides the semantic conventions of OpenTelemetry.
+const (
+	attrTitle        = attribute.Key("shortdescription.title")
+	attrTitles       = attribute.Key("shortdescription.titles")
+	attrCache        = attribute.Key("shortdescription.cache")
+	attrCacheOutcome = attribute.Key("shortdescription.cache.outcome")
+	attrCacheHits    = attribute.Key("shortdescription.cache.hits")
+	attrAPI          = attribute.Key("shortdescription.upstream.api")
+	attrAttempt      = attribute.Key("shortdescription.upstream.attempt")
+	attrBytesScanned = attribute.Key("shortdescription.upstream.bytes_scanned")
+	attrRequestID    = attribute.Key("shortdescription.request_id")
+)
+
+// endSpan ends span, marking it as failed if err is not the client's nor a missing
+// description.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		if status, _ := errorCode(err); status >= http.StatusInternalServerError {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+
+	span.End()
+}
+
+// traceUpstream starts the span of a request to the Wikimedia APIs, whose trace context is
+// sent along with it.
+func (d Describer) traceUpstream(req *http.Request, api string, attempt int) (*http.Request, trace.Span) {
+	ctx, span := d.tracer.Start(req.Context(), "GET "+api,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPMethod(req.Method),
+			semconv.HTTPURL(req.URL.String()),
+			attrAPI.String(api),
+			attrAttempt.Int(attempt),
+		),
+	)
+
+	req = req.WithContext(ctx)
+	d.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	return req, span
+}
+
+// tracedBody ends the span of an upstream request once its response is closed, telling
+// how much of it was read.
+type tracedBody struct {
+	io.ReadCloser
+	span trace.Span
+
+	read int64
+	once sync.Once
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	b.once.Do(func() {
+		b.span.SetAttributes(attrBytesScanned.Int64(b.read))
+		b.span.End()
+	})
+
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,133 @@
+package shortdescription_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var upstreamTraceparent string
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient: handlerClient(func(w http.ResponseWriter, req *http.Request) {
+			upstreamTraceparent = req.Header.Get("traceparent")
+			_, _ = w.Write([]byte(testContent))
+		}),
+		TracerProvider: provider,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/descriptions/yoshua_Bengio?api_key=s3cr3t", nil)
+	req.Header.Set("User-Agent", testUserAgent)
+	req.Header.Set("traceparent", traceparent)
+
+	w := httptest.NewRecorder()
+	shortdescription.NewHandler(descriptor).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("wanted 200, got %d: %s", w.Code, w.Body)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	testCases := []struct {
+		span       string
+		kind       trace.SpanKind
+		parent     string
+		attributes []attribute.KeyValue
+	}{
+		{
+			span: "GET /v1/descriptions/{person}",
+			kind: trace.SpanKindServer,
+			attributes: []attribute.KeyValue{
+				attribute.String("http.route", "/v1/descriptions/{person}"),
+				attribute.String("http.target", "/v1/descriptions/yoshua_Bengio"),
+				attribute.Int("http.status_code", http.StatusOK),
+			},
+		},
+		{
+			span:   "ShortDescription",
+			kind:   trace.SpanKindInternal,
+			parent: "GET /v1/descriptions/{person}",
+			attributes: []attribute.KeyValue{
+				attribute.String("shortdescription.title", testPerson),
+			},
+		},
+		{
+			span:   "cache.lookup",
+			kind:   trace.SpanKindInternal,
+			parent: "ShortDescription",
+			attributes: []attribute.KeyValue{
+				attribute.String("shortdescription.title", testPerson),
+				attribute.String("shortdescription.cache.outcome", "miss"),
+			},
+		},
+		{
+			span:   "GET action",
+			kind:   trace.SpanKindClient,
+			parent: "ShortDescription",
+			attributes: []attribute.KeyValue{
+				attribute.Int("shortdescription.upstream.attempt", 1),
+				attribute.Int("http.status_code", http.StatusOK),
+				attribute.Int64("shortdescription.upstream.bytes_scanned", int64(len(testContent))),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.span, func(t *testing.T) {
+			span, ok := spans[tc.span]
+			if !ok {
+				t.Fatalf("wanted a span named %q", tc.span)
+			}
+
+			if span.SpanKind() != tc.kind {
+				t.Errorf("wanted a %v span, got %v", tc.kind, span.SpanKind())
+			}
+
+			if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("wanted the trace of the traceparent header, got %v", span.SpanContext().TraceID())
+			}
+
+			if tc.parent != "" && span.Parent().SpanID() != spans[tc.parent].SpanContext().SpanID() {
+				t.Errorf("wanted %q to be the parent", tc.parent)
+			}
+
+			attributes := make(map[attribute.Key]attribute.Value)
+			for _, kv := range span.Attributes() {
+				attributes[kv.Key] = kv.Value
+			}
+
+			for _, kv := range tc.attributes {
+				if value := attributes[kv.Key]; value != kv.Value {
+					t.Errorf("wanted %s to be %v, got %v", kv.Key, kv.Value.Emit(), value.Emit())
+				}
+			}
+		})
+	}
+
+	upstream := propagation.TraceContext{}.Extract(req.Context(), propagation.HeaderCarrier{"Traceparent": {upstreamTraceparent}})
+	if spanContext := trace.SpanContextFromContext(upstream); spanContext.SpanID() != spans["GET action"].SpanContext().SpanID() {
+		t.Errorf("wanted the upstream request to continue the trace, got traceparent %q", upstreamTraceparent)
+	}
+}
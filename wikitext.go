@@ -0,0 +1,607 @@
+package shortdescription
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+const (
+	maxTemplateNameLen = 256
+	maxTemplateLen     = 64 << 10
+)
+
+var (
+	errTemplateTooLong = errors.New("template is too long")
+	errNoArgument      = errors.New("template has no description argument")
+)
+
+// findShortDescription scans wikitext until it finds a short description, given either by
+// the {{Short description}} template or the {{SHORTDESC:}} magic word, and returns it as
+// plain text. Anything inside HTML comments is ignored. It fails with errNoArgument if the
+// template has no description at all, as in {{Short description}}, which is not the same as
+// an explicitly empty one.
+//
+// Only the template being looked for is held in memory, so the page can be streamed.
+func findShortDescription(r io.RuneScanner, skipped func(name string, delim rune)) (string, error) {
+	name, body, err := findTemplate(r, func(name string, delim rune) bool {
+		normalized := normalizeTemplateName(name)
+		if delim == ':' && normalized == "shortdesc" || delim != ':' && normalized == "short description" {
+			return true
+		}
+
+		if skipped != nil {
+			skipped(name, delim)
+		}
+
+		return false
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if normalizeTemplateName(name) == "shortdesc" {
+		// the magic word takes its value in place of the first argument
+		return plainText(splitArgs(body)[0]), nil
+	}
+
+	// the body starts with the pipe that separates the name from the first argument
+	positional := positionalArgs(splitArgs(body)[1:])
+	if len(positional) == 0 {
+		return "", errNoArgument
+	}
+
+	return positional[0], nil
+}
+
+// findTemplate scans wikitext until it finds a template whose name matches, and returns
+// its body: everything between the name and the closing "}}". Templates nested inside
+// others are visited too, but not the ones inside HTML comments.
+func findTemplate(r io.RuneScanner, match func(name string, delim rune) bool) (name, body string, err error) {
+	for {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return "", "", err
+		}
+
+		switch c {
+		case '<':
+			if _, err := skipComment(r); err != nil {
+				return "", "", err
+			}
+		case '{':
+			next, _, err := r.ReadRune()
+			if err != nil {
+				return "", "", err
+			}
+
+			if next != '{' {
+				_ = r.UnreadRune()
+				continue
+			}
+
+			name, delim, err := readTemplateName(r)
+			if err != nil {
+				return "", "", err
+			}
+
+			if delim == 0 || !match(name, delim) {
+				continue
+			}
+
+			body, err := readTemplateBody(r)
+
+			return name, body, err
+		}
+	}
+}
+
+// readTemplateName reads the name of the template that starts right after a "{{", along with
+// the delimiter that ends it: '|' or '}', which are left unread, or ':' for magic words and
+// parser functions. If the name is cut short by something else, such as a nested template,
+// delim is 0 and the scanner is left right before it.
+func readTemplateName(r io.RuneScanner) (name string, delim rune, err error) {
+	var b strings.Builder
+
+	for b.Len() < maxTemplateNameLen {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return "", 0, err
+		}
+
+		switch c {
+		case '<':
+			skipped, err := skipComment(r)
+			if err != nil {
+				return "", 0, err
+			}
+
+			if !skipped {
+				return b.String(), 0, nil
+			}
+
+		case ':':
+			if strings.EqualFold(strings.TrimSpace(b.String()), "template") {
+				b.WriteRune(c)
+				continue
+			}
+
+			return b.String(), c, nil
+
+		case '|', '}':
+			_ = r.UnreadRune()
+			return b.String(), c, nil
+
+		case '{', '[', ']':
+			_ = r.UnreadRune()
+			return b.String(), 0, nil
+
+		default:
+			b.WriteRune(c)
+		}
+	}
+
+	return b.String(), 0, nil
+}
+
+// normalizeTemplateName lower-cases a template name, leaving out the Template: namespace
+// and any redundant whitespace.
+func normalizeTemplateName(name string) string {
+	name = strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(name, "_", " ")), " "))
+	return strings.TrimSpace(strings.TrimPrefix(name, "template:"))
+}
+
+// readTemplateBody reads until the "}}" that closes the current template, leaving out comments.
+func readTemplateBody(r io.RuneScanner) (string, error) {
+	var (
+		body  strings.Builder
+		depth int
+	)
+
+	for body.Len() < maxTemplateLen {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch c {
+		case '<':
+			skipped, err := skipComment(r)
+			if err != nil {
+				return "", err
+			}
+
+			if !skipped {
+				body.WriteRune(c)
+			}
+
+		case '{', '}':
+			next, _, err := r.ReadRune()
+			if err != nil {
+				return "", err
+			}
+
+			if next != c {
+				_ = r.UnreadRune()
+				body.WriteRune(c)
+
+				continue
+			}
+
+			if c == '}' {
+				if depth == 0 {
+					return body.String(), nil
+				}
+
+				depth--
+			} else {
+				depth++
+			}
+
+			body.WriteString(string([]rune{c, c}))
+
+		default:
+			body.WriteRune(c)
+		}
+	}
+
+	return "", errTemplateTooLong
+}
+
+// skipComment is called right after a '<' and skips an HTML comment if there's one.
+func skipComment(r io.RuneScanner) (bool, error) {
+	for _, expected := range "!--" {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return false, err
+		}
+
+		if c != expected {
+			_ = r.UnreadRune()
+			return false, nil
+		}
+	}
+
+	var dashes int
+
+	for {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case c == '-':
+			dashes++
+		case c == '>' && dashes >= 2:
+			return true, nil
+		default:
+			dashes = 0
+		}
+	}
+}
+
+// splitArgs splits a template on the pipes that are not part of a nested template or link.
+func splitArgs(s string) []string {
+	var (
+		args  []string
+		depth int
+		start int
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "{{") || strings.HasPrefix(s[i:], "[["):
+			depth++
+			i++
+		case (strings.HasPrefix(s[i:], "}}") || strings.HasPrefix(s[i:], "]]")) && depth > 0:
+			depth--
+			i++
+		case s[i] == '|' && depth == 0:
+			args = append(args, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(args, s[start:])
+}
+
+// namedArg returns the name and value of a "name=value" argument.
+func namedArg(arg string) (name, value string, ok bool) {
+	i := strings.IndexByte(arg, '=')
+	if i < 0 || strings.ContainsAny(arg[:i], "{[<") {
+		return "", "", false
+	}
+
+	return strings.TrimSpace(arg[:i]), arg[i+1:], true
+}
+
+// positionalArgs returns the positional arguments of a template as plain text, taking
+// explicitly numbered arguments such as "1=" into account.
+func positionalArgs(args []string) []string {
+	var positional []string
+
+	numbered := map[int]string{}
+
+	for _, arg := range args {
+		name, value, ok := namedArg(arg)
+		if !ok {
+			positional = append(positional, plainText(arg))
+			continue
+		}
+
+		if n, err := strconv.Atoi(name); err == nil && n > 0 {
+			numbered[n] = plainText(value)
+		}
+	}
+
+	for n, value := range numbered {
+		for len(positional) < n {
+			positional = append(positional, "")
+		}
+
+		positional[n-1] = value
+	}
+
+	return positional
+}
+
+// plainText strips the wikitext markup out of s: comments, references, HTML tags, bold and
+// italics are removed, links are replaced by their label and the templates that are usual
+// inside descriptions by their text. Other templates are dropped.
+func plainText(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "<!--"):
+			end := strings.Index(s[i:], "-->")
+			if end < 0 {
+				i = len(s)
+				continue
+			}
+
+			i += end + len("-->")
+
+		case strings.HasPrefix(s[i:], "{{"):
+			end := closing(s, i, "{{", "}}")
+			if end < 0 {
+				i = len(s)
+				continue
+			}
+
+			b.WriteString(templateText(splitArgs(s[i+2 : end])))
+			i = end + 2
+
+		case strings.HasPrefix(s[i:], "[["):
+			end := closing(s, i, "[[", "]]")
+			if end < 0 {
+				i = len(s)
+				continue
+			}
+
+			b.WriteString(linkText(s[i+2 : end]))
+			i = end + 2
+
+		case s[i] == '[' && isExternalLink(s[i+1:]):
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				i = len(s)
+				continue
+			}
+
+			if _, label, ok := strings.Cut(s[i+1:i+end], " "); ok {
+				b.WriteString(plainText(label))
+			}
+
+			i += end + 1
+
+		case strings.HasPrefix(s[i:], "''"):
+			for i < len(s) && s[i] == '\'' {
+				i++
+			}
+
+		case s[i] == '<' && isTag(s[i+1:]):
+			i = skipTag(s, i)
+
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+
+	return strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+}
+
+// closing returns the index of the close delimiter matching the open one at s[i:].
+func closing(s string, i int, open, close string) int {
+	var depth int
+
+	for ; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], open):
+			depth++
+			i += len(open) - 1
+		case strings.HasPrefix(s[i:], close):
+			depth--
+			if depth == 0 {
+				return i
+			}
+
+			i += len(close) - 1
+		}
+	}
+
+	return -1
+}
+
+// templateText renders the templates that commonly show up inside short descriptions.
+func templateText(args []string) string {
+	name := normalizeTemplateName(args[0])
+	positional := positionalArgs(args[1:])
+
+	arg := func(n int) string {
+		if n > len(positional) {
+			return ""
+		}
+
+		return positional[n-1]
+	}
+
+	switch name {
+	case "nowrap", "nobr", "nobreak", "no wrap", "small", "smaller", "big", "em", "strong",
+		"ill", "interlanguage link", "lang-rtl":
+		return arg(1)
+	case "lang", "transl", "transliteration", "script":
+		return arg(len(positional))
+	case "circa", "c.":
+		return "c. " + arg(1)
+	case "ndash", "en dash":
+		return "–"
+	case "mdash", "em dash":
+		return "—"
+	case "snd", "spaced ndash", "spaced en dash":
+		return " – "
+	case "nbsp", "space":
+		return " "
+	case "!":
+		return "|"
+	case "'":
+		return "'"
+	}
+
+	if strings.HasPrefix(name, "lang-") {
+		return arg(1)
+	}
+
+	return ""
+}
+
+func linkText(link string) string {
+	target, label, hasLabel := strings.Cut(link, "|")
+
+	namespace, _, _ := strings.Cut(strings.TrimSpace(target), ":")
+	switch strings.ToLower(namespace) {
+	case "file", "image", "category", "media":
+		return ""
+	}
+
+	if !hasLabel || strings.TrimSpace(label) == "" {
+		label = strings.TrimPrefix(strings.TrimSpace(target), ":")
+	}
+
+	return plainText(label)
+}
+
+func isExternalLink(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "//")
+}
+
+func isTag(s string) bool {
+	return s != "" && (s[0] == '/' || unicode.IsLetter(rune(s[0])))
+}
+
+// skipTag skips the HTML tag at s[i:], along with its content if it's a reference.
+func skipTag(s string, i int) int {
+	end := strings.IndexByte(s[i:], '>')
+	if end < 0 {
+		return len(s)
+	}
+
+	tag := s[i+1 : i+end]
+	next := i + end + 1
+
+	fields := strings.FieldsFunc(tag, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '/'
+	})
+
+	if len(fields) == 0 || !strings.EqualFold(fields[0], "ref") || strings.HasSuffix(tag, "/") || strings.HasPrefix(tag, "/") {
+		return next
+	}
+
+	closeTag := strings.Index(strings.ToLower(s[next:]), "</ref>")
+	if closeTag < 0 {
+		return len(s)
+	}
+
+	return next + closeTag + len("</ref>")
+}
+
+// jsonTextReader reads the runes of a JSON document, replacing escape sequences by the
+// characters they stand for. It lets wikitext inside API responses be scanned as it's
+// streamed, without having to decode the whole response first.
+type jsonTextReader struct {
+	r *bufio.Reader
+
+	last     rune
+	lastSize int
+	unread   bool
+}
+
+func newJSONTextReader(r io.Reader) *jsonTextReader {
+	return &jsonTextReader{r: bufio.NewReader(r)}
+}
+
+func (j *jsonTextReader) ReadRune() (rune, int, error) {
+	if j.unread {
+		j.unread = false
+		return j.last, j.lastSize, nil
+	}
+
+	c, size, err := j.r.ReadRune()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if c == '\\' {
+		var n int
+
+		c, n, err = j.readEscaped()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		size += n
+	}
+
+	j.last, j.lastSize = c, size
+
+	return c, size, nil
+}
+
+func (j *jsonTextReader) UnreadRune() error {
+	if j.unread || j.lastSize == 0 {
+		return bufio.ErrInvalidUnreadRune
+	}
+
+	j.unread = true
+
+	return nil
+}
+
+func (j *jsonTextReader) readEscaped() (rune, int, error) {
+	c, size, err := j.r.ReadRune()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	switch c {
+	case 'n':
+		return '\n', size, nil
+	case 't':
+		return '\t', size, nil
+	case 'r':
+		return '\r', size, nil
+	case 'b':
+		return '\b', size, nil
+	case 'f':
+		return '\f', size, nil
+	case 'u':
+		c, err := j.readHex()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		size += 4
+
+		if !utf16.IsSurrogate(c) {
+			return c, size, nil
+		}
+
+		// the second half of a surrogate pair is expected as another \u escape
+		if next, err := j.r.Peek(2); err != nil || string(next) != `\u` {
+			return unicode.ReplacementChar, size, nil
+		}
+
+		_, _ = j.r.Discard(2)
+
+		low, err := j.readHex()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return utf16.DecodeRune(c, low), size + 6, nil
+	default: // '"', '\\' and '/' stand for themselves
+		return c, size, nil
+	}
+}
+
+func (j *jsonTextReader) readHex() (rune, error) {
+	hex := make([]byte, 4)
+	if _, err := io.ReadFull(j.r, hex); err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.ParseUint(string(hex), 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid unicode escape %q", ErrUpstream, hex)
+	}
+
+	return rune(n), nil
+}
@@ -0,0 +1,167 @@
+package shortdescription_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	shortdescription "github.com/Inuart/wikimedia-exercise"
+)
+
+// wikitextFixtures are short description variants found on English Wikipedia pages.
+var wikitextFixtures = []struct {
+	name        string
+	content     wikiJSON
+	expected    string
+	expectedErr error
+}{
+	{
+		name:     "canonical template",
+		content:  "{{Short description|Canadian computer scientist}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "lower-case first letter",
+		content:  "{{short description|Canadian computer scientist}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "whitespace around name and argument",
+		content:  "{{ Short description | Canadian computer scientist }}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "underscores and a newline in the name",
+		content:  "{{Short_description\n|Canadian computer scientist}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "template namespace prefix",
+		content:  "{{Template:Short description|Canadian computer scientist}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "magic word",
+		content:  "{{SHORTDESC:Canadian computer scientist}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "magic word with noreplace",
+		content:  "{{SHORTDESC:Canadian computer scientist|noreplace}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "noreplace parameter",
+		content:  "{{Short description|Canadian computer scientist|noreplace}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "named pagetype parameter",
+		content:  "{{Short description|pagetype=Biography|Canadian computer scientist}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "explicitly numbered parameter",
+		content:  "{{Short description|1=Canadian computer scientist}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "comment inside the description",
+		content:  "{{Short description|Canadian <!-- not French --> computer scientist}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "commented out template is ignored",
+		content:  "<!-- {{Short description|Old description}} -->\n{{Short description|Canadian computer scientist}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "wikilinks",
+		content:  "{{Short description|[[Canada|Canadian]] [[computer scientist]]}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "nested templates",
+		content:  "{{Short description|French {{lang|fr|chanteuse}} ({{circa|1900}}{{ndash}}1950)}}",
+		expected: "French chanteuse (c. 1900–1950)",
+	},
+	{
+		name:     "unknown nested templates are dropped",
+		content:  "{{Short description|Canadian computer scientist{{citation needed|date=May 2020}}}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "bold, italics, tags and entities",
+		content:  "{{Short description|''Canadian'' '''computer''' <small>scientist</small>&nbsp;&amp; author}}",
+		expected: "Canadian computer scientist & author",
+	},
+	{
+		name:     "references are dropped",
+		content:  `{{Short description|Canadian computer scientist<ref name="bio">[https://example.org Bio]</ref>}}`,
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "external link label",
+		content:  "{{Short description|Author of [https://example.org Deep Learning]}}",
+		expected: "Author of Deep Learning",
+	},
+	{
+		name:     "description after other templates",
+		content:  "{{Use mdy dates|date=March 2019}}\n{{Infobox scientist\n|name = {{nowrap|Yoshua Bengio}}}}\n{{Short description|Canadian computer scientist}}",
+		expected: "Canadian computer scientist",
+	},
+	{
+		name:     "JSON escape sequences",
+		content:  `{"content":"{{Short description|Canadian \"AI\" pioneer à Montréal 🤖}}\n"}`,
+		expected: `Canadian "AI" pioneer à Montréal 🤖`,
+	},
+	{
+		name:        "template without description",
+		content:     "{{Short description}}",
+		expectedErr: shortdescription.ErrNotFound,
+	},
+	{
+		name:     "explicitly empty description",
+		content:  "{{Short description|}}",
+		expected: "",
+	},
+	{
+		name:        "unterminated template",
+		content:     "{{Short description|Canadian computer scientist",
+		expectedErr: shortdescription.ErrNotFound,
+	},
+	{
+		name:        "similarly named templates",
+		content:     "{{Short descriptions|Not it}}{{Long description|Not it either}}",
+		expectedErr: shortdescription.ErrNotFound,
+	},
+}
+
+func TestWikitextFixtures(t *testing.T) {
+	ctx := context.Background()
+	var mockClient mockHttpClient
+
+	descriptor, err := shortdescription.New(shortdescription.Config{
+		ContactInfo: testContactInfo,
+		HttpClient:  &mockClient,
+		CachedTTL:   -1, // remove caching
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range wikitextFixtures {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient.body = tc.content
+
+			descr, err := descriptor.ShortDescription(ctx, testPerson, testUserAgent)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("wanted %v, got %v", tc.expectedErr, err)
+			}
+
+			if descr.Description != tc.expected {
+				t.Errorf("wanted %q, got %q", tc.expected, descr.Description)
+			}
+		})
+	}
+}